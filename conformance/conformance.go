@@ -0,0 +1,194 @@
+// Package conformance runs a real implementation in lockstep with the model, and checks that it behaves as the model.
+//
+// The implementation is driven through an Adapter. For each transition of the model, the adapter has an exported
+// method with the name of the transition, no arguments and an error result, e.g. for the "IncA" transition:
+//
+//	func (a *myAdapter) IncA() error
+//
+// The method applies the action to the system under test.
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/jakub-m/formaggo/state"
+)
+
+// Adapter connects the system under test to the model.
+type Adapter interface {
+	// Reset brings the system to the initial state of the model.
+	Reset() error
+	// Observe returns the state of the system, converted to a state of the model.
+	Observe() (interface{}, error)
+}
+
+// Mismatch is the first step where the system did not behave as the model.
+type Mismatch struct {
+	// Trace is the full model trace being run.
+	Trace state.Trace
+	// Step is the index of Trace.States where the system diverged. 0 is the state after Reset.
+	Step int
+	// Observed is the state of the system at Step, or nil if Err is set.
+	Observed interface{}
+	// Err is the error returned by the adapter, if any.
+	Err error
+}
+
+func (m Mismatch) String() string {
+	s := ""
+	if m.Step > 0 {
+		s = fmt.Sprintf("Mismatch at step %d, after %s\n", m.Step, m.Trace.Transitions[m.Step-1])
+	} else {
+		s = "Mismatch at the initial state\n"
+	}
+	for i, st := range m.Trace.States {
+		if i == m.Step {
+			s += fmt.Sprintf("%d\texpected %v\n", i, st)
+			if m.Err != nil {
+				s += fmt.Sprintf("\terror: %s\n", m.Err)
+			} else {
+				s += fmt.Sprintf("\tobserved %v\n", m.Observed)
+			}
+		} else {
+			s += fmt.Sprintf("%d\t%v\n", i, st)
+		}
+		if i < len(m.Trace.Transitions) {
+			s += fmt.Sprintf("%d->%d\t%s\n", i, i+1, m.Trace.Transitions[i])
+		}
+	}
+	return s
+}
+
+// Check runs the system along traces covering every edge of the graph, see StateGraph.TestTraces.
+func Check(adapter Adapter, g state.StateGraph) (*Mismatch, error) {
+	return Run(adapter, g.TestTraces(state.CoverEdges))
+}
+
+// Run resets the system before each of the traces, applies the transitions of the trace and compares the observed
+// states with the states of the trace, by hash. Since the observed state must be the state of the trace, the
+// transitions with many possible next states should be avoided. Returns the first mismatch, or nil if the system
+// conforms to the traces. The error is returned if the adapter lacks a method for a transition.
+func Run(adapter Adapter, traces []state.Trace) (*Mismatch, error) {
+	actions, err := findActions(adapter, traces)
+	if err != nil {
+		return nil, err
+	}
+	for _, trace := range traces {
+		if m := runTrace(adapter, actions, trace); m != nil {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func runTrace(adapter Adapter, actions map[string]func() error, trace state.Trace) *Mismatch {
+	if err := adapter.Reset(); err != nil {
+		return &Mismatch{Trace: trace, Step: 0, Err: err}
+	}
+	for i, expected := range trace.States {
+		if i > 0 {
+			if err := actions[trace.Transitions[i-1]](); err != nil {
+				return &Mismatch{Trace: trace, Step: i, Err: err}
+			}
+		}
+		observed, err := adapter.Observe()
+		if err != nil {
+			return &Mismatch{Trace: trace, Step: i, Err: err}
+		}
+		if state.GetHash(observed) != state.GetHash(expected) {
+			return &Mismatch{Trace: trace, Step: i, Observed: observed}
+		}
+	}
+	return nil
+}
+
+// CheckInvariant resets the system, applies the transitions, and checks the invariant on each observed step. The
+// observed states must be the expected states, given as JSON, since with a transition of many possible next states the
+// system might take another branch than the counterexample. Returns an error if a state differs, if the invariant does
+// not hold, or if the adapter fails.
+func CheckInvariant(adapter Adapter, transitions []string, states []string, inv state.Invariant) error {
+	if len(states) != len(transitions)+1 {
+		return fmt.Errorf("expected %d states for %d transitions, got %d", len(transitions)+1, len(transitions), len(states))
+	}
+	actions, err := findActions(adapter, []state.Trace{{Transitions: transitions}})
+	if err != nil {
+		return err
+	}
+	if err := adapter.Reset(); err != nil {
+		return err
+	}
+	curr, err := adapter.Observe()
+	if err != nil {
+		return err
+	}
+	if err := compareJSON(curr, states[0]); err != nil {
+		return fmt.Errorf("initial state: %w", err)
+	}
+	for i, name := range transitions {
+		if err := actions[name](); err != nil {
+			return fmt.Errorf("step %d->%d %s: %w", i, i+1, name, err)
+		}
+		next, err := adapter.Observe()
+		if err != nil {
+			return fmt.Errorf("step %d->%d %s: %w", i, i+1, name, err)
+		}
+		if err := compareJSON(next, states[i+1]); err != nil {
+			return fmt.Errorf("step %d->%d %s: %w", i, i+1, name, err)
+		}
+		if !inv(curr, next) {
+			return fmt.Errorf("invariant does not hold at step %d->%d %s: %v -> %v", i, i+1, name, curr, next)
+		}
+		curr = next
+	}
+	return nil
+}
+
+// compareJSON returns an error if the observed state encoded as JSON is not the expected JSON.
+func compareJSON(observed interface{}, expected string) error {
+	b, err := json.Marshal(observed)
+	if err != nil {
+		return err
+	}
+	var want bytes.Buffer
+	if err := json.Compact(&want, []byte(expected)); err != nil {
+		return fmt.Errorf("expected state is not JSON: %w", err)
+	}
+	if !bytes.Equal(b, want.Bytes()) {
+		return fmt.Errorf("observed state %s, expected %s", b, want.Bytes())
+	}
+	return nil
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// findActions finds the methods of the adapter for all the transitions of the traces.
+func findActions(adapter Adapter, traces []state.Trace) (map[string]func() error, error) {
+	actions := make(map[string]func() error)
+	v := reflect.ValueOf(adapter)
+	for _, trace := range traces {
+		for _, name := range trace.Transitions {
+			if _, ok := actions[name]; ok {
+				continue
+			}
+			m := v.MethodByName(name)
+			if !m.IsValid() {
+				return nil, fmt.Errorf("adapter %T has no method %s", adapter, name)
+			}
+			t := m.Type()
+			if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0) != errorType {
+				return nil, fmt.Errorf("method %s of adapter %T must have type func() error, has %s", name, adapter, t)
+			}
+			actions[name] = func() error {
+				out := m.Call(nil)
+				if out[0].IsNil() {
+					return nil
+				}
+				return out[0].Interface().(error)
+			}
+		}
+	}
+	return actions, nil
+}
@@ -0,0 +1,82 @@
+package conformance
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jakub-m/formaggo/internal/testmodel"
+	"github.com/jakub-m/formaggo/state"
+	"github.com/stretchr/testify/assert"
+)
+
+// system is the implementation under test. With the bug, B wraps at 4 instead of 3.
+type system struct {
+	a, b int
+	bug  bool
+}
+
+func (s *system) Reset() error {
+	s.a, s.b = 0, 0
+	return nil
+}
+
+func (s *system) Observe() (interface{}, error) {
+	return testmodel.Counters{A: s.a, B: s.b}, nil
+}
+
+func (s *system) IncA() error {
+	s.a = (s.a + 1) % 3
+	return nil
+}
+
+func (s *system) IncB() error {
+	limit := 3
+	if s.bug {
+		limit = 4
+	}
+	s.b = (s.b + 1) % limit
+	return nil
+}
+
+func TestCheck(t *testing.T) {
+	g, _, _ := testmodel.Checker(3).Run()
+	m, err := Check(&system{}, g)
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+}
+
+func TestCheckMismatch(t *testing.T) {
+	g, _, _ := testmodel.Checker(3).Run()
+	m, err := Check(&system{bug: true}, g)
+	assert.NoError(t, err)
+	if assert.NotNil(t, m) {
+		assert.Equal(t, "IncB", m.Trace.Transitions[m.Step-1])
+		assert.Equal(t, 0, m.Trace.States[m.Step].(testmodel.Counters).B)
+		assert.Equal(t, 3, m.Observed.(testmodel.Counters).B)
+		assert.Contains(t, m.String(), "after IncB")
+	}
+}
+
+type failingSystem struct {
+	system
+}
+
+func (s *failingSystem) IncA() error {
+	return errors.New("boom")
+}
+
+func TestRunAdapterError(t *testing.T) {
+	trace := state.Trace{States: []interface{}{testmodel.Counters{}, testmodel.Counters{A: 1}}, Transitions: []string{"IncA"}}
+	m, err := Run(&failingSystem{}, []state.Trace{trace})
+	assert.NoError(t, err)
+	if assert.NotNil(t, m) {
+		assert.Equal(t, 1, m.Step)
+		assert.EqualError(t, m.Err, "boom")
+	}
+}
+
+func TestRunMissingMethod(t *testing.T) {
+	trace := state.Trace{States: []interface{}{testmodel.Counters{}, testmodel.Counters{}}, Transitions: []string{"IncC"}}
+	_, err := Run(&system{}, []state.Trace{trace})
+	assert.EqualError(t, err, "adapter *conformance.system has no method IncC")
+}
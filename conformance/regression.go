@@ -0,0 +1,118 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/jakub-m/formaggo/state"
+)
+
+// RegressionTest generates a Go test from an invariant violation. The test replays the transitions of the
+// counterexample through the adapter, compares the observed states with the states of the counterexample, and checks
+// the invariant on the real system, see CheckInvariant.
+type RegressionTest struct {
+	// Package is the package of the generated file.
+	Package string
+	// Name is the name of the test function. Defaults to "TestRegression" followed by the name of the invariant.
+	Name string
+	// Adapter is a Go expression returning the Adapter, e.g. "newAdapter()".
+	Adapter string
+	// Invariant is a Go expression of the invariant to check. Defaults to the name of the violated invariant.
+	Invariant string
+}
+
+var regressionTemplate = template.Must(template.New("regression").Parse(`// Code generated by formaggo from a violation of {{.InvariantName}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"testing"
+
+	"github.com/jakub-m/formaggo/conformance"
+)
+
+// {{.Name}} replays the counterexample found by the model:
+//
+{{range .TraceLines}}//	{{.}}
+{{end}}func {{.Name}}(t *testing.T) {
+	transitions := []string{
+{{range .Transitions}}		{{printf "%q" .}},
+{{end}}	}
+	states := []string{
+{{range .States}}		{{printf "%q" .}},
+{{end}}	}
+	if err := conformance.CheckInvariant({{.Adapter}}, transitions, states, {{.Invariant}}); err != nil {
+		t.Fatal(err)
+	}
+}
+`))
+
+// WriteFile writes the test to the file, which should be named *_test.go.
+func (rt RegressionTest) WriteFile(path string, v state.Violation) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return rt.Write(f, v)
+}
+
+// Write writes the source of the test. Only the violations of invariants are supported.
+func (rt RegressionTest) Write(w io.Writer, v state.Violation) error {
+	if v.Inv == nil {
+		return fmt.Errorf("only invariant violations can be turned into regression tests")
+	}
+	if rt.Package == "" || rt.Adapter == "" {
+		return fmt.Errorf("package and adapter of the regression test must be set")
+	}
+	if rt.Name == "" {
+		rt.Name = "TestRegression" + v.Inv.Name
+	}
+	if rt.Invariant == "" {
+		rt.Invariant = v.Inv.Name
+	}
+	trace := v.Trace()
+	for i, name := range trace.Transitions {
+		if name == state.IllegalTransition {
+			return fmt.Errorf("no transition of the model leads from state %d to %d of the violation", i, i+1)
+		}
+	}
+	states := []string{}
+	for _, st := range trace.States {
+		b, err := json.Marshal(st)
+		if err != nil {
+			return err
+		}
+		states = append(states, string(b))
+	}
+	data := struct {
+		RegressionTest
+		InvariantName string
+		TraceLines    []string
+		Transitions   []string
+		States        []string
+	}{
+		RegressionTest: rt,
+		InvariantName:  v.Inv.Name,
+		TraceLines:     strings.Split(strings.TrimRight(trace.String(), "\n"), "\n"),
+		Transitions:    trace.Transitions,
+		States:         states,
+	}
+
+	var buf bytes.Buffer
+	if err := regressionTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated test does not parse: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
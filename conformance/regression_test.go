@@ -0,0 +1,79 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/jakub-m/formaggo/internal/testmodel"
+	"github.com/jakub-m/formaggo/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func ABelow2(curr, next interface{}) bool {
+	return next.(testmodel.Counters).A < 2
+}
+
+func getViolation(t *testing.T) state.Violation {
+	checker := testmodel.Checker(3)
+	checker.NamedInvariants = []state.NamedInvariant{{Name: "ABelow2", Inv: ABelow2}}
+	_, _, violation := checker.Run()
+	if !assert.NotNil(t, violation) {
+		t.FailNow()
+	}
+	return *violation
+}
+
+func TestRegressionTestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := RegressionTest{Package: "counters", Adapter: "&system{}"}.Write(&buf, getViolation(t))
+	assert.NoError(t, err)
+	src := buf.String()
+	assert.Contains(t, src, "package counters\n")
+	assert.Contains(t, src, "func TestRegressionABelow2(t *testing.T) {")
+	assert.Contains(t, src, "\"IncA\",\n")
+	assert.Contains(t, src, "\"{\\\"A\\\":0,\\\"B\\\":0}\",\n")
+	assert.Contains(t, src, "conformance.CheckInvariant(&system{}, transitions, states, ABelow2)")
+}
+
+func TestRegressionTestWriteNotReproducible(t *testing.T) {
+	violation := getViolation(t)
+	violation.Path = []interface{}{testmodel.Counters{}, testmodel.Counters{A: 2}}
+	err := RegressionTest{Package: "counters", Adapter: "&system{}"}.Write(&bytes.Buffer{}, violation)
+	assert.EqualError(t, err, "no transition of the model leads from state 0 to 1 of the violation")
+}
+
+func TestRegressionTestWriteProperty(t *testing.T) {
+	err := RegressionTest{Package: "counters", Adapter: "&system{}"}.Write(&bytes.Buffer{}, state.Violation{
+		Prop: &state.NamedTemporalProperty{Name: "Prop"},
+	})
+	assert.Error(t, err)
+}
+
+func traceStatesJSON(t *testing.T, trace state.Trace) []string {
+	states := []string{}
+	for _, st := range trace.States {
+		b, err := json.Marshal(st)
+		assert.NoError(t, err)
+		states = append(states, string(b))
+	}
+	return states
+}
+
+func TestCheckInvariant(t *testing.T) {
+	trace := getViolation(t).Trace()
+	err := CheckInvariant(&system{}, trace.Transitions, traceStatesJSON(t, trace), ABelow2)
+	if assert.Error(t, err) {
+		assert.Regexp(t, `^invariant does not hold at step \d+->\d+ IncA: \{1 \d\} -> \{2 \d\}$`, err.Error())
+	}
+	assert.NoError(t, CheckInvariant(&system{}, []string{"IncA", "IncB"}, []string{`{"A":0,"B":0}`, `{"A":1,"B":0}`, `{"A":1,"B":1}`}, ABelow2))
+}
+
+func TestCheckInvariantStateDiffers(t *testing.T) {
+	transitions := []string{"IncB", "IncB", "IncB"}
+	states := []string{`{"A":0,"B":0}`, `{"A":0,"B":1}`, `{"A":0,"B":2}`, `{"A":0,"B":0}`}
+	err := CheckInvariant(&system{bug: true}, transitions, states, ABelow2)
+	assert.EqualError(t, err, `step 2->3 IncB: observed state {"A":0,"B":3}, expected {"A":0,"B":0}`)
+	err = CheckInvariant(&system{}, transitions, states[:2], ABelow2)
+	assert.EqualError(t, err, "expected 4 states for 3 transitions, got 2")
+}
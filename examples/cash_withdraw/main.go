@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	fo "github.com/jakub-m/formaggo/state"
+	"github.com/jakub-m/formaggo/sweep"
 )
 
 // From https://learntla.com/introduction/example/
@@ -14,57 +16,66 @@ const (
 )
 
 func main() {
+	sw := sweep.Sweep{
+		Params: []sweep.Param{sweep.Range("m0", 1, 20), sweep.Range("m1", 1, 20)},
+		Build:  buildChecker,
+	}
+	results := sw.Run()
+	fmt.Print(results)
+	failed := results.Failed()
+	if len(failed) == 0 {
+		return
+	}
+	for _, r := range failed {
+		if r.Violation != nil {
+			fmt.Print(r.Violation.Format(fo.FormatPlain))
+			break
+		}
+	}
+	os.Exit(1)
+}
+
+// buildChecker builds the checker for the processes withdrawing the money m0 and m1.
+func buildChecker(params sweep.Params) fo.Checker {
 	s := State{
 		AccountAlice: 10,
 		AccountBob:   10,
 	}
 	s.P[0].Step = stepCheck
 	s.P[1].Step = stepCheck
-	for m0 := 1; m0 <= 20; m0++ {
-		for m1 := 1; m1 <= 20; m1++ {
-			s.P[0].Money = m0
-			s.P[1].Money = m1
-
-			checker := fo.Checker{
-				InitialState: s,
-				NamedTransitions: []fo.NamedTransition{
-					{
-						Name:       "ProcCheck",
-						Transition: fo.Managed(ProcCheck),
-					},
-					{
-						Name:       "ProcTransfer",
-						Transition: fo.Managed(ProcTransfer),
-					},
-				},
-				NamedInvariants: []fo.NamedInvariant{
-					{
-						Name: "TypeInvariant",
-						Inv:  TypeInvariant,
-					},
-					{
-						Name: "MoneyNonNegativeInvariant",
-						Inv:  MoneyNonNegativeInvariant,
-					},
-					{
-						Name: "CheckFinalBalance",
-						Inv:  CheckFinalBalance,
-					},
-					{
-						Name: "TotalMoneyInvariant",
-						Inv:  TotalMoneyInvariant,
-					},
-				},
-			}
-			// TODO here reuse output graph in another iteration.
-			_, violation := checker.Run()
-			if violation != nil {
-				for i, p := range violation.Path {
-					fmt.Println(i, p)
-				}
-				panic(fmt.Sprint("VIOLATION ", violation.Inv.Name))
-			}
-		}
+	s.P[0].Money = params["m0"]
+	s.P[1].Money = params["m1"]
+
+	return fo.Checker{
+		InitialState: s,
+		NamedTransitions: []fo.NamedTransition{
+			{
+				Name:       "ProcCheck",
+				Transition: fo.Managed(ProcCheck),
+			},
+			{
+				Name:       "ProcTransfer",
+				Transition: fo.Managed(ProcTransfer),
+			},
+		},
+		NamedInvariants: []fo.NamedInvariant{
+			{
+				Name: "TypeInvariant",
+				Inv:  TypeInvariant,
+			},
+			{
+				Name: "MoneyNonNegativeInvariant",
+				Inv:  MoneyNonNegativeInvariant,
+			},
+			{
+				Name: "CheckFinalBalance",
+				Inv:  CheckFinalBalance,
+			},
+			{
+				Name: "TotalMoneyInvariant",
+				Inv:  TotalMoneyInvariant,
+			},
+		},
 	}
 }
 
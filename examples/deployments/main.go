@@ -6,7 +6,9 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 
+	"github.com/jakub-m/formaggo/runner"
 	sta "github.com/jakub-m/formaggo/state"
 )
 
@@ -15,13 +17,21 @@ const (
 )
 
 func main() {
+	runner.Main(newChecker(), runner.Command{
+		Name:  "quotient",
+		Usage: "print the size of the graph where only the end of the deployment is observable",
+		Run:   printQuotient,
+	})
+}
+
+func newChecker() sta.Checker {
 	initialState := State{}
 	iterServers(func(i int) {
 		initialState.LoadBalancer[i] = true
 		initialState.Version[i] = UpdateState_OldVersion
 	})
 
-	checker := sta.Checker{
+	return sta.Checker{
 		InitialState: initialState,
 		NamedTransitions: []sta.NamedTransition{
 			{
@@ -74,14 +84,19 @@ func main() {
 			},
 		},
 	}
+}
 
-	graph, violation := checker.Run()
-	fmt.Printf("Number of states: %d\n", graph.NumStates())
-
+// printQuotient prints the size of a smaller graph, where only the end of the deployment is observable.
+func printQuotient(checker sta.Checker, w io.Writer) error {
+	graph, _, violation := checker.Run()
 	if violation != nil {
-		fmt.Print(violation)
+		fmt.Fprint(w, violation.Format(sta.FormatColor))
+		return fmt.Errorf("no quotient of a model with a violation")
 	}
-	//graph.ExportToDotFile("deployment.dot")
+	quotient := graph.Bisimulation(func(s interface{}) interface{} { return PropAllDeployed(s) })
+	fmt.Fprintf(w, "Number of states: %d\n", graph.NumStates())
+	fmt.Fprintf(w, "Number of states observing only PropAllDeployed: %d\n", quotient.Graph.NumStates())
+	return nil
 }
 
 // Arrays of fixed size are very practical here. Such State has no heap referenes, and does not need any "copy"
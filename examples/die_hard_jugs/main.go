@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"io"
 
+	"github.com/jakub-m/formaggo/runner"
 	fo "github.com/jakub-m/formaggo/state"
 )
 
@@ -10,13 +12,22 @@ import (
 // exactly 4 gallons of water.
 
 func main() {
+	runner.Main(newChecker(), runner.Command{
+		Name:  "solve",
+		Usage: "print the steps to measure 4 gallons",
+		Run:   solve,
+	})
+}
 
-	checker := fo.Checker{
+func newChecker() fo.Checker {
+	return fo.Checker{
 		InitialState: Jugs{Jug3: 0, Jug5: 0},
 		NamedTransitions: []fo.NamedTransition{
 			{
 				Transition: fo.Managed(EmptyOrFillJugs),
 				Name:       "EmptyOrFillJugs",
+				// Filling and emptying the jugs is free, so FindState finds the plan with the fewest pours.
+				Cost: func(curr, next interface{}) int { return 0 },
 			},
 			{
 				Transition: fo.Managed(PourJug3ToJug5),
@@ -32,24 +43,23 @@ func main() {
 				Name: "InvJugSize",
 				Inv:  InvJugSize,
 			},
-			{
-				Name: "InvEndCondition",
-				Inv:  InvEndCondition,
-			},
 			{
 				Name: "InvConstantWater",
 				Inv:  InvConstantWater,
 			},
 		},
 	}
+}
 
-	_, violation := checker.Run()
-	if violation != nil {
-		fmt.Println(violation.Inv.Name)
-		for i, p := range violation.Path {
-			fmt.Println(i, p)
-		}
+// solve prints the cheapest way to measure 4 gallons.
+func solve(checker fo.Checker, w io.Writer) error {
+	trace, found := checker.FindState(EndCondition)
+	if !found {
+		fmt.Fprintln(w, "There is no way to measure 4 gallons")
+		return nil
 	}
+	fmt.Fprint(w, trace)
+	return nil
 }
 
 type Jugs struct {
@@ -125,9 +135,8 @@ func InvJugSize(currIn, nextIn interface{}) bool {
 	return (curr.Jug3 >= 0 && curr.Jug3 <= 3) && (curr.Jug5 >= 0 && curr.Jug5 <= 5)
 }
 
-func InvEndCondition(currIn, nextIn interface{}) bool {
-	curr := currIn.(Jugs)
-	return curr.Jug5 != 4
+func EndCondition(in interface{}) bool {
+	return in.(Jugs).Jug5 == 4
 }
 
 func InvConstantWater(currIn, nextIn interface{}) bool {
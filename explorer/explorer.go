@@ -0,0 +1,320 @@
+// Package explorer serves a web page for browsing a state graph, e.g. one too large to look at as a DOT picture. The
+// page and its script are served by the binary, so it works offline.
+//
+//	graph, _, violation := checker.Run()
+//	e := explorer.Explorer{Graph: graph}
+//	if violation != nil {
+//		e.Violations = append(e.Violations, *violation)
+//	}
+//	log.Fatal(e.ListenAndServe("localhost:8080"))
+package explorer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jakub-m/formaggo/state"
+)
+
+const (
+	// defaultSearchLimit is the number of the search results returned if the request does not set it.
+	defaultSearchLimit = 100
+	// maxLabelLength is the length the printed states are cut to in the lists.
+	maxLabelLength = 120
+)
+
+// Explorer serves the state graph over HTTP.
+type Explorer struct {
+	Graph state.StateGraph
+	// Violations are shown as traces, with their states highlighted.
+	Violations []state.Violation
+	// Predicates are the named conditions the states can be searched by.
+	Predicates map[string]state.StateCondition
+}
+
+// ListenAndServe serves the explorer at the address, e.g. "localhost:8080".
+func (e Explorer) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, e.Handler())
+}
+
+// Handler returns the handler serving the page at "/" and the JSON API at "/api/".
+func (e Explorer) Handler() http.Handler {
+	idx := newIndex(e)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, pageHTML)
+	})
+	mux.HandleFunc("/api/info", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, idx.info())
+	})
+	mux.HandleFunc("/api/states", func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultSearchLimit
+		if l := r.URL.Query().Get("limit"); l != "" {
+			n, err := strconv.Atoi(l)
+			if err != nil || n <= 0 {
+				http.Error(w, fmt.Sprintf("bad limit: %s", l), http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		results, err := idx.search(r.URL.Query().Get("predicate"), r.URL.Query().Get("q"), limit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, results)
+	})
+	mux.HandleFunc("/api/state", func(w http.ResponseWriter, r *http.Request) {
+		details, ok := idx.details(r.URL.Query().Get("id"))
+		if !ok {
+			http.Error(w, "no such state", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, details)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type stateRef struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+type edgeRef struct {
+	stateRef
+	Transitions []string `json:"transitions"`
+}
+
+type traceStep struct {
+	stateRef
+	// Transition leads to the state, empty for the first state.
+	Transition string `json:"transition"`
+}
+
+type violationInfo struct {
+	Title string      `json:"title"`
+	Steps []traceStep `json:"steps"`
+}
+
+type info struct {
+	Initial    stateRef        `json:"initial"`
+	NumStates  int             `json:"numStates"`
+	Predicates []string        `json:"predicates"`
+	Violations []violationInfo `json:"violations"`
+}
+
+type stateDetails struct {
+	stateRef
+	JSON         json.RawMessage `json:"json"`
+	Successors   []edgeRef       `json:"successors"`
+	Predecessors []edgeRef       `json:"predecessors"`
+	// Violations are the indices of the violations with the state on their trace.
+	Violations []int `json:"violations"`
+}
+
+// index finds the states by their ids, which are the printed hashes.
+type index struct {
+	e          Explorer
+	states     []interface{}
+	byID       map[string]interface{}
+	violations []violationInfo
+}
+
+func newIndex(e Explorer) *index {
+	idx := &index{e: e, states: e.Graph.States(), byID: make(map[string]interface{})}
+	for _, s := range idx.states {
+		idx.byID[stateID(s)] = s
+	}
+	for _, v := range e.Violations {
+		vi := violationInfo{Title: violationTitle(v)}
+		trace := v.Trace()
+		for i, s := range trace.States {
+			// The next state of an invariant violation is not in the graph.
+			idx.byID[stateID(s)] = s
+			step := traceStep{stateRef: ref(s)}
+			if i > 0 {
+				step.Transition = trace.Transitions[i-1]
+			}
+			vi.Steps = append(vi.Steps, step)
+		}
+		idx.violations = append(idx.violations, vi)
+	}
+	return idx
+}
+
+func (idx *index) info() info {
+	names := []string{}
+	for name := range idx.e.Predicates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return info{
+		Initial:    ref(idx.e.Graph.InitialState()),
+		NumStates:  len(idx.states),
+		Predicates: names,
+		Violations: idx.violations,
+	}
+}
+
+// search returns the states meeting the named predicate (if set) and the query, see matchQuery.
+func (idx *index) search(predicate, query string, limit int) ([]stateRef, error) {
+	var cond state.StateCondition
+	if predicate != "" {
+		var ok bool
+		if cond, ok = idx.e.Predicates[predicate]; !ok {
+			return nil, fmt.Errorf("no predicate %s", predicate)
+		}
+	}
+	results := []stateRef{}
+	for _, s := range idx.states {
+		if len(results) == limit {
+			break
+		}
+		if cond != nil && !cond(s) {
+			continue
+		}
+		if matchQuery(s, query) {
+			results = append(results, ref(s))
+		}
+	}
+	return results, nil
+}
+
+func (idx *index) details(id string) (stateDetails, bool) {
+	s, ok := idx.byID[id]
+	if !ok {
+		return stateDetails{}, false
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(s))
+	}
+	d := stateDetails{
+		stateRef:     ref(s),
+		JSON:         b,
+		Successors:   []edgeRef{},
+		Predecessors: []edgeRef{},
+		Violations:   []int{},
+	}
+	for _, e := range idx.e.Graph.OutgoingEdges(s) {
+		d.Successors = append(d.Successors, edgeRef{stateRef: ref(e.To), Transitions: e.Transitions})
+	}
+	for _, e := range idx.e.Graph.IncomingEdges(s) {
+		d.Predecessors = append(d.Predecessors, edgeRef{stateRef: ref(e.From), Transitions: e.Transitions})
+	}
+	sort.Slice(d.Predecessors, func(i, j int) bool { return d.Predecessors[i].ID < d.Predecessors[j].ID })
+	for i, v := range idx.violations {
+		for _, step := range v.Steps {
+			if step.ID == id {
+				d.Violations = append(d.Violations, i)
+				break
+			}
+		}
+	}
+	return d, true
+}
+
+// matchQuery matches the state against the space separated terms of the query. A term "path=value" matches if the
+// field at the path (e.g. "Version.1" for the second element of the Version array) of the state marshalled to JSON
+// is printed as the value. Any other term must be a substring of the JSON of the state. An empty query matches all
+// the states.
+func matchQuery(s interface{}, query string) bool {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return true
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return false
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return false
+	}
+	for _, term := range terms {
+		if path, value, ok := cutTerm(term); ok {
+			if v, found := lookupJSON(decoded, path); !found || printJSONValue(v) != value {
+				return false
+			}
+		} else if !strings.Contains(string(b), term) {
+			return false
+		}
+	}
+	return true
+}
+
+func cutTerm(term string) (string, string, bool) {
+	i := strings.Index(term, "=")
+	if i <= 0 {
+		return "", "", false
+	}
+	return term[:i], term[i+1:], true
+}
+
+// lookupJSON follows the dot separated path of object keys and array indices.
+func lookupJSON(v interface{}, path string) (interface{}, bool) {
+	for _, part := range strings.Split(path, ".") {
+		switch x := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = x[part]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, false
+			}
+			v = x[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func printJSONValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func ref(s interface{}) stateRef {
+	label := fmt.Sprint(s)
+	if len(label) > maxLabelLength {
+		label = label[:maxLabelLength] + "..."
+	}
+	return stateRef{ID: stateID(s), Label: label}
+}
+
+func stateID(s interface{}) string {
+	return fmt.Sprint(state.GetHash(s))
+}
+
+func violationTitle(v state.Violation) string {
+	if v.Inv != nil {
+		return "Violation of invariant " + v.Inv.Name
+	}
+	if v.Prop != nil {
+		return "Violation of property " + v.Prop.Name
+	}
+	return "Violation"
+}
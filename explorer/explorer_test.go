@@ -0,0 +1,85 @@
+package explorer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jakub-m/formaggo/internal/testmodel"
+	"github.com/jakub-m/formaggo/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func getExplorer(t *testing.T) Explorer {
+	checker := testmodel.Checker(3)
+	checker.NamedInvariants = []state.NamedInvariant{testmodel.ABelow(2)}
+	g, _, violation := checker.Run()
+	assert.NotNil(t, violation)
+	return Explorer{
+		Graph:      g,
+		Violations: []state.Violation{*violation},
+		Predicates: map[string]state.StateCondition{
+			"BIsOne": func(s interface{}) bool { return s.(testmodel.Counters).B == 1 },
+		},
+	}
+}
+
+func getJSON(t *testing.T, server *httptest.Server, path string, v interface{}) int {
+	resp, err := http.Get(server.URL + path)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func TestExplorer(t *testing.T) {
+	server := httptest.NewServer(getExplorer(t).Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	assert.NoError(t, err)
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(page), "<title>Formaggo explorer</title>")
+
+	var i info
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/info", &i))
+	assert.Equal(t, stateID(testmodel.Counters{}), i.Initial.ID)
+	assert.Equal(t, []string{"BIsOne"}, i.Predicates)
+	if assert.Len(t, i.Violations, 1) {
+		assert.Equal(t, "Violation of invariant ABelow2", i.Violations[0].Title)
+	}
+
+	var results []stateRef
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/states?predicate=BIsOne&q=A%3D0", &results))
+	assert.Equal(t, []stateRef{ref(testmodel.Counters{B: 1})}, results)
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/states?q=A%3D1+B%3D0", &results))
+	assert.Equal(t, []stateRef{ref(testmodel.Counters{A: 1})}, results)
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, server, "/api/states?predicate=Foo", &results))
+
+	var d stateDetails
+	assert.Equal(t, http.StatusOK, getJSON(t, server, "/api/state?id="+stateID(testmodel.Counters{}), &d))
+	assert.JSONEq(t, `{"A":0,"B":0}`, string(d.JSON))
+	assert.Contains(t, d.Successors, edgeRef{stateRef: ref(testmodel.Counters{A: 1}), Transitions: []string{"IncA"}})
+	assert.Equal(t, []int{0}, d.Violations)
+	assert.Equal(t, http.StatusNotFound, getJSON(t, server, "/api/state?id=1", &d))
+}
+
+func TestMatchQuery(t *testing.T) {
+	s := struct {
+		A    int
+		Tags [2]string
+	}{A: 1, Tags: [2]string{"x", "y"}}
+	assert.True(t, matchQuery(s, ""))
+	assert.True(t, matchQuery(s, "A=1 Tags.1=y"))
+	assert.False(t, matchQuery(s, "A=1 Tags.1=x"))
+	assert.False(t, matchQuery(s, "C=1"))
+	assert.True(t, matchQuery(s, `"x"`))
+	assert.False(t, matchQuery(s, "z"))
+}
@@ -0,0 +1,148 @@
+package explorer
+
+// pageHTML is the explorer page. It talks to the JSON API of the Handler.
+const pageHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Formaggo explorer</title>
+<style>
+body { font-family: sans-serif; margin: 0; display: flex; height: 100vh; color: #222; }
+#side { width: 30em; overflow: auto; padding: 1em; border-right: 1px solid #ccc; }
+#main { flex: 1; overflow: auto; padding: 1em; }
+input, select, button { font-size: 1em; }
+#query { width: 100%; box-sizing: border-box; }
+ul { list-style: none; padding: 0; }
+li { font-family: monospace; padding: 0.15em 0.3em; cursor: pointer; }
+li:hover { background: #eef; }
+li.onTrace { background: #fde8e8; }
+li.current { font-weight: bold; }
+.label { color: #06c; margin-right: 0.5em; }
+pre { background: #f6f6f6; padding: 0.5em; overflow: auto; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<div id="side">
+<h2>Search</h2>
+<select id="predicate"><option value="">(any state)</option></select>
+<p><input id="query" placeholder="Field.0=value or text"></p>
+<button id="search">Search</button> <button id="initial">Initial state</button>
+<p id="searchInfo"></p>
+<ul id="results"></ul>
+<h2>Violations</h2>
+<ul id="violations"></ul>
+<ul id="trace"></ul>
+</div>
+<div id="main">
+<h2 id="title">Select a state</h2>
+<p id="onViolations"></p>
+<pre id="json"></pre>
+<h3>Successors</h3>
+<ul id="successors"></ul>
+<h3>Predecessors</h3>
+<ul id="predecessors"></ul>
+</div>
+<script>
+var info = null;
+var currentTrace = -1;
+var currentID = "";
+
+function $(id) { return document.getElementById(id); }
+
+function get(url, fn) {
+  fetch(url).then(function (r) {
+    if (!r.ok) { return r.text().then(function (t) { throw new Error(t); }); }
+    return r.json();
+  }).then(fn).catch(function (err) {
+    $("searchInfo").textContent = err.message;
+    $("searchInfo").className = "error";
+  });
+}
+
+function item(text, label, onClick) {
+  var li = document.createElement("li");
+  if (label) {
+    var span = document.createElement("span");
+    span.className = "label";
+    span.textContent = label;
+    li.appendChild(span);
+  }
+  li.appendChild(document.createTextNode(text));
+  li.addEventListener("click", onClick);
+  return li;
+}
+
+function fill(list, items) {
+  list.innerHTML = "";
+  items.forEach(function (li) { list.appendChild(li); });
+}
+
+function onTrace(id) {
+  if (currentTrace < 0) { return false; }
+  return info.violations[currentTrace].steps.some(function (s) { return s.id === id; });
+}
+
+function edgeItems(edges) {
+  return edges.map(function (e) {
+    var li = item(e.label, e.transitions.join(", "), function () { show(e.id); });
+    if (onTrace(e.id)) { li.classList.add("onTrace"); }
+    return li;
+  });
+}
+
+function show(id) {
+  currentID = id;
+  get("/api/state?id=" + encodeURIComponent(id), function (d) {
+    $("title").textContent = d.label;
+    $("json").textContent = JSON.stringify(d.json, null, 2);
+    $("onViolations").textContent = d.violations.length ? "On the trace of: " + d.violations.map(function (i) { return info.violations[i].title; }).join(", ") : "";
+    fill($("successors"), edgeItems(d.successors));
+    fill($("predecessors"), edgeItems(d.predecessors));
+    showTrace(currentTrace);
+  });
+}
+
+function showTrace(index) {
+  currentTrace = index;
+  if (index < 0) { fill($("trace"), []); return; }
+  fill($("trace"), info.violations[index].steps.map(function (step, i) {
+    var li = item(step.label, i + (step.transition ? " " + step.transition : ""), function () { show(step.id); });
+    li.classList.add("onTrace");
+    if (step.id === currentID) { li.classList.add("current"); }
+    return li;
+  }));
+}
+
+function search() {
+  var url = "/api/states?predicate=" + encodeURIComponent($("predicate").value) + "&q=" + encodeURIComponent($("query").value);
+  get(url, function (results) {
+    $("searchInfo").className = "";
+    $("searchInfo").textContent = results.length + " states (of " + info.numStates + ")";
+    fill($("results"), results.map(function (r) { return item(r.label, "", function () { show(r.id); }); }));
+  });
+}
+
+get("/api/info", function (i) {
+  info = i;
+  i.predicates.forEach(function (p) {
+    var o = document.createElement("option");
+    o.value = p;
+    o.textContent = p;
+    $("predicate").appendChild(o);
+  });
+  fill($("violations"), i.violations.map(function (v, index) {
+    return item(v.title, "", function () {
+      showTrace(index);
+      show(v.steps[v.steps.length - 1].id);
+    });
+  }));
+  show(i.initial.id);
+});
+$("search").addEventListener("click", search);
+$("query").addEventListener("keydown", function (e) { if (e.key === "Enter") { search(); } });
+$("initial").addEventListener("click", function () { show(info.initial.id); });
+</script>
+</body>
+</html>
+`
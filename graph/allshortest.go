@@ -0,0 +1,47 @@
+package graph
+
+// AllShortestPaths returns all the shortest paths from start to end in an unweighted graph. Beware, the number of the
+// paths can grow exponentially with the size of the graph.
+func AllShortestPaths(start, end Vertex, getNext func(Vertex) []Vertex) []Path {
+	dist := map[Vertex]int{start: 0}
+	// prevs are all the neighbours on the shortest paths from start.
+	prevs := map[Vertex][]Vertex{}
+	level := []Vertex{start}
+	for len(level) > 0 {
+		if _, ok := dist[end]; ok {
+			break
+		}
+		nextLevel := []Vertex{}
+		for _, v := range level {
+			for _, next := range getNext(v) {
+				d, ok := dist[next]
+				if !ok {
+					dist[next] = dist[v] + 1
+					nextLevel = append(nextLevel, next)
+				} else if d != dist[v]+1 {
+					continue
+				}
+				prevs[next] = append(prevs[next], v)
+			}
+		}
+		level = nextLevel
+	}
+	if _, ok := dist[end]; !ok {
+		return []Path{}
+	}
+
+	paths := []Path{}
+	var rec func(v Vertex, suffix []Vertex)
+	rec = func(v Vertex, suffix []Vertex) {
+		suffix = append([]Vertex{v}, suffix...)
+		if v == start {
+			paths = append(paths, Path{Vertices: suffix, Cost: len(suffix) - 1, Found: true})
+			return
+		}
+		for _, prev := range prevs[v] {
+			rec(prev, suffix)
+		}
+	}
+	rec(end, nil)
+	return paths
+}
@@ -0,0 +1,78 @@
+package graph
+
+// BidirectionalShortestPath returns the shortest path from start to end in an unweighted graph, searching from both
+// ends at once. getPrev returns the vertices with an edge to the given vertex. It explores far fewer vertices than
+// ShortestPath when the graph branches a lot.
+func BidirectionalShortestPath(start, end Vertex, getNext, getPrev func(Vertex) []Vertex) Path {
+	if start == end {
+		return Path{Vertices: []Vertex{start}, Found: true}
+	}
+	forward := newBfsSide(start, getNext)
+	backward := newBfsSide(end, getPrev)
+	for len(forward.level) > 0 && len(backward.level) > 0 {
+		// Expand the smaller side, and always the whole level, so the first meeting found is the shortest one.
+		side, other := forward, backward
+		if len(backward.level) < len(forward.level) {
+			side, other = backward, forward
+		}
+		meeting, found := side.expandLevel(other)
+		if found {
+			p := Path{Found: true}
+			for v := meeting; ; v = forward.prev[v] {
+				p.Vertices = append(p.Vertices, v)
+				if v == start {
+					break
+				}
+			}
+			reverse(p.Vertices)
+			for v := meeting; v != end; {
+				v = backward.prev[v]
+				p.Vertices = append(p.Vertices, v)
+			}
+			p.Cost = len(p.Vertices) - 1
+			return p
+		}
+	}
+	return Path{Vertices: []Vertex{}}
+}
+
+type bfsSide struct {
+	getNext func(Vertex) []Vertex
+	dist    map[Vertex]int
+	// prev is the neighbour towards the root of this side.
+	prev  map[Vertex]Vertex
+	level []Vertex
+}
+
+func newBfsSide(root Vertex, getNext func(Vertex) []Vertex) *bfsSide {
+	return &bfsSide{
+		getNext: getNext,
+		dist:    map[Vertex]int{root: 0},
+		prev:    map[Vertex]Vertex{},
+		level:   []Vertex{root},
+	}
+}
+
+// expandLevel visits the next level, and returns the vertex where the two sides meet on the shortest path, if any.
+func (s *bfsSide) expandLevel(other *bfsSide) (Vertex, bool) {
+	var meeting Vertex
+	bestDist := -1
+	nextLevel := []Vertex{}
+	for _, v := range s.level {
+		for _, next := range s.getNext(v) {
+			if _, ok := s.dist[next]; ok {
+				continue
+			}
+			s.dist[next] = s.dist[v] + 1
+			s.prev[next] = v
+			nextLevel = append(nextLevel, next)
+			if otherDist, ok := other.dist[next]; ok {
+				if d := s.dist[next] + otherDist; bestDist == -1 || d < bestDist {
+					meeting, bestDist = next, d
+				}
+			}
+		}
+	}
+	s.level = nextLevel
+	return meeting, bestDist != -1
+}
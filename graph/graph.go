@@ -0,0 +1,167 @@
+// Package graph has search algorithms over graphs given as functions returning the neighbours of a vertex, so the
+// graphs can be built on the fly. A vertex can be of any comparable type (e.g. an int, a string or a struct of
+// those), since the vertices are used as map keys.
+package graph
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+type Vertex interface{}
+
+// Edge is an edge to the vertex To. The Cost must not be negative.
+type Edge struct {
+	To   Vertex
+	Cost int
+}
+
+// Path is a result of a search. If Found is false, there is no path and Vertices is empty. A path from a vertex to
+// itself has a single vertex.
+type Path struct {
+	Vertices []Vertex
+	Cost     int
+	Found    bool
+}
+
+// Unweighted converts getNext of an unweighted graph to a getNext of a graph where each edge costs 1.
+func Unweighted(getNext func(Vertex) []Vertex) func(Vertex) []Edge {
+	return func(v Vertex) []Edge {
+		edges := []Edge{}
+		for _, next := range getNext(v) {
+			edges = append(edges, Edge{To: next, Cost: 1})
+		}
+		return edges
+	}
+}
+
+// ShortestPath returns the shortest path from start to end in an unweighted graph (BFS).
+func ShortestPath(start, end Vertex, getNext func(Vertex) []Vertex) Path {
+	prev := map[Vertex]Vertex{}
+	visited := map[Vertex]bool{start: true}
+	level := []Vertex{start}
+	for len(level) > 0 {
+		nextLevel := []Vertex{}
+		for _, v := range level {
+			if v == end {
+				return traceBack(start, end, prev, func(Vertex, Vertex) int { return 1 })
+			}
+			for _, next := range getNext(v) {
+				if !visited[next] {
+					visited[next] = true
+					prev[next] = v
+					nextLevel = append(nextLevel, next)
+				}
+			}
+		}
+		level = nextLevel
+	}
+	return Path{Vertices: []Vertex{}}
+}
+
+// CheapestPath returns the cheapest path from start to the first vertex for which isEnd is true (Dijkstra). getNext
+// is called at most once per vertex.
+func CheapestPath(start Vertex, isEnd func(Vertex) bool, getNext func(Vertex) []Edge) Path {
+	return AStar(start, isEnd, getNext, func(Vertex) int { return 0 })
+}
+
+// AStar returns the cheapest path from start to the first vertex for which isEnd is true, using the heuristic to
+// explore the promising vertices first. The heuristic is an estimate of the cost from a vertex to the end. It must
+// not overestimate the cost, otherwise the path might not be the cheapest one.
+func AStar(start Vertex, isEnd func(Vertex) bool, getNext func(Vertex) []Edge, heuristic func(Vertex) int) Path {
+	backlog := &backlogHeap{}
+	heap.Push(backlog, &backlogItem{vertex: start, cost: 0, estimate: heuristic(start)})
+	costs := map[Vertex]int{start: 0}
+	prev := map[Vertex]Vertex{} // this is to trace back the path
+
+	for backlog.Len() > 0 {
+		item := heap.Pop(backlog).(*backlogItem)
+		current := item.vertex
+		if item.cost > costs[current] {
+			// A stale entry, the vertex was already reached with a lower cost.
+			continue
+		}
+		if isEnd(current) {
+			return traceBack(start, current, prev, func(from, to Vertex) int { return costs[to] - costs[from] })
+		}
+		for _, edge := range getNext(current) {
+			if edge.Cost < 0 {
+				panic(fmt.Sprintf("negative cost of edge %v -> %v: %d", current, edge.To, edge.Cost))
+			}
+			tentativeCost := item.cost + edge.Cost
+			if cost, ok := costs[edge.To]; ok && cost <= tentativeCost {
+				continue
+			}
+			costs[edge.To] = tentativeCost
+			prev[edge.To] = current
+			heap.Push(backlog, &backlogItem{vertex: edge.To, cost: tentativeCost, estimate: tentativeCost + heuristic(edge.To)})
+		}
+	}
+	return Path{Vertices: []Vertex{}}
+}
+
+// traceBack follows prev from end to start, and returns the path from start to end.
+func traceBack(start, end Vertex, prev map[Vertex]Vertex, edgeCost func(from, to Vertex) int) Path {
+	p := Path{Vertices: []Vertex{end}, Found: true}
+	current := end
+	for current != start {
+		previous, ok := prev[current]
+		if !ok {
+			panic(fmt.Sprintf("RATS! No back-hop for vertex: %v", current))
+		}
+		p.Vertices = append(p.Vertices, previous)
+		p.Cost += edgeCost(previous, current)
+		current = previous
+	}
+	reverse(p.Vertices)
+	return p
+}
+
+func reverse(vv []Vertex) {
+	for i, j := 0, len(vv)-1; i < j; i, j = i+1, j-1 {
+		vv[i], vv[j] = vv[j], vv[i]
+	}
+}
+
+type backlogItem struct {
+	vertex Vertex
+	cost   int
+	// estimate is the cost plus the heuristic.
+	estimate int
+	// order makes the items with equal estimates pop in the order of pushing, so the results are deterministic.
+	order int
+}
+
+type backlogHeap struct {
+	items   []*backlogItem
+	counter int
+}
+
+func (h backlogHeap) Len() int {
+	return len(h.items)
+}
+
+func (h backlogHeap) Less(i, j int) bool {
+	if h.items[i].estimate != h.items[j].estimate {
+		return h.items[i].estimate < h.items[j].estimate
+	}
+	return h.items[i].order < h.items[j].order
+}
+
+func (h backlogHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+}
+
+func (h *backlogHeap) Push(x interface{}) {
+	item := x.(*backlogItem)
+	item.order = h.counter
+	h.counter++
+	h.items = append(h.items, item)
+}
+
+func (h *backlogHeap) Pop() interface{} {
+	n := len(h.items)
+	x := h.items[n-1]
+	h.items = h.items[0 : n-1]
+	return x
+}
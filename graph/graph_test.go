@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getDiamond returns a graph a -> b|c -> d -> e, with an additional, longer, route a -> f -> g -> h -> e.
+func getDiamond() map[Vertex][]Vertex {
+	return map[Vertex][]Vertex{
+		"a": {"b", "c", "f"},
+		"b": {"d"},
+		"c": {"d"},
+		"d": {"e"},
+		"f": {"g"},
+		"g": {"h"},
+		"h": {"e"},
+	}
+}
+
+func nextFromMap(m map[Vertex][]Vertex) func(Vertex) []Vertex {
+	return func(v Vertex) []Vertex { return m[v] }
+}
+
+func prevFromMap(m map[Vertex][]Vertex) func(Vertex) []Vertex {
+	reversed := map[Vertex][]Vertex{}
+	for v, nn := range m {
+		for _, n := range nn {
+			reversed[n] = append(reversed[n], v)
+		}
+	}
+	return nextFromMap(reversed)
+}
+
+func vertices(vv ...Vertex) []Vertex {
+	return vv
+}
+
+func TestShortestPath(t *testing.T) {
+	getNext := nextFromMap(getDiamond())
+	assert.Equal(t, Path{Vertices: vertices("a", "b", "d", "e"), Cost: 3, Found: true}, ShortestPath("a", "e", getNext))
+	assert.Equal(t, Path{Vertices: vertices("a"), Cost: 0, Found: true}, ShortestPath("a", "a", getNext))
+	assert.Equal(t, Path{Vertices: []Vertex{}}, ShortestPath("e", "a", getNext))
+}
+
+func TestCheapestPath(t *testing.T) {
+	getNext := func(v Vertex) []Edge {
+		return map[Vertex][]Edge{
+			1: {{2, 10}, {3, 1}},
+			2: {{4, 1}},
+			3: {{2, 1}, {4, 20}},
+		}[v]
+	}
+	isEnd := func(v Vertex) bool { return v == 4 }
+	assert.Equal(t, Path{Vertices: vertices(1, 3, 2, 4), Cost: 3, Found: true}, CheapestPath(1, isEnd, getNext))
+	assert.Panics(t, func() {
+		CheapestPath(1, isEnd, func(Vertex) []Edge { return []Edge{{2, -1}} })
+	})
+}
+
+func TestAStarGrid(t *testing.T) {
+	type point struct{ X, Y int }
+	abs := func(x int) int {
+		if x < 0 {
+			return -x
+		}
+		return x
+	}
+	wall := func(p point) bool { return p.X == 2 && p.Y < 4 }
+	getNext := func(v Vertex) []Edge {
+		p := v.(point)
+		edges := []Edge{}
+		for _, d := range []point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			n := point{p.X + d.X, p.Y + d.Y}
+			if n.X >= 0 && n.Y >= 0 && n.X < 5 && n.Y < 5 && !wall(n) {
+				edges = append(edges, Edge{n, 1})
+			}
+		}
+		return edges
+	}
+	end := point{4, 0}
+	manhattan := func(v Vertex) int {
+		p := v.(point)
+		return abs(p.X-end.X) + abs(p.Y-end.Y)
+	}
+	isEnd := func(v Vertex) bool { return v == end }
+	p := AStar(point{0, 0}, isEnd, getNext, manhattan)
+	assert.True(t, p.Found)
+	assert.Equal(t, 12, p.Cost)
+	assert.Equal(t, CheapestPath(point{0, 0}, isEnd, getNext).Cost, p.Cost)
+}
+
+func TestBidirectionalShortestPath(t *testing.T) {
+	m := getDiamond()
+	p := BidirectionalShortestPath("a", "e", nextFromMap(m), prevFromMap(m))
+	assert.True(t, p.Found)
+	assert.Equal(t, 3, p.Cost)
+	assert.Len(t, p.Vertices, 4)
+	assert.Equal(t, Vertex("a"), p.Vertices[0])
+	assert.Equal(t, Vertex("d"), p.Vertices[2])
+	assert.Equal(t, Vertex("e"), p.Vertices[3])
+
+	assert.Equal(t, Path{Vertices: vertices("g", "h", "e"), Cost: 2, Found: true},
+		BidirectionalShortestPath("g", "e", nextFromMap(m), prevFromMap(m)))
+	assert.Equal(t, Path{Vertices: vertices("a"), Found: true},
+		BidirectionalShortestPath("a", "a", nextFromMap(m), prevFromMap(m)))
+	assert.False(t, BidirectionalShortestPath("e", "a", nextFromMap(m), prevFromMap(m)).Found)
+}
+
+func TestKCheapestPaths(t *testing.T) {
+	getNext := Unweighted(nextFromMap(getDiamond()))
+	paths := KCheapestPaths("a", "e", 5, getNext)
+	assert.Equal(t, []Path{
+		{Vertices: vertices("a", "b", "d", "e"), Cost: 3, Found: true},
+		{Vertices: vertices("a", "c", "d", "e"), Cost: 3, Found: true},
+		{Vertices: vertices("a", "f", "g", "h", "e"), Cost: 4, Found: true},
+	}, paths)
+	assert.Len(t, KCheapestPaths("a", "e", 2, getNext), 2)
+	assert.Empty(t, KCheapestPaths("e", "a", 2, getNext))
+}
+
+func TestAllShortestPaths(t *testing.T) {
+	paths := AllShortestPaths("a", "e", nextFromMap(getDiamond()))
+	assert.ElementsMatch(t, []Path{
+		{Vertices: vertices("a", "b", "d", "e"), Cost: 3, Found: true},
+		{Vertices: vertices("a", "c", "d", "e"), Cost: 3, Found: true},
+	}, paths)
+	assert.Equal(t, []Path{{Vertices: vertices("a"), Found: true}}, AllShortestPaths("a", "a", nextFromMap(getDiamond())))
+	assert.Empty(t, AllShortestPaths("e", "a", nextFromMap(getDiamond())))
+}
@@ -0,0 +1,97 @@
+package graph
+
+import "sort"
+
+// KCheapestPaths returns up to k cheapest paths from start to end without loops, ordered by the cost (Yen's
+// algorithm). For an unweighted graph use Unweighted(getNext).
+func KCheapestPaths(start, end Vertex, k int, getNext func(Vertex) []Edge) []Path {
+	isEnd := func(v Vertex) bool { return v == end }
+	first := CheapestPath(start, isEnd, getNext)
+	if !first.Found || k < 1 {
+		return []Path{}
+	}
+	found := []Path{first}
+	candidates := []Path{}
+	for len(found) < k {
+		last := found[len(found)-1]
+		// Deviate from the last path at each of its vertices (the spur), keeping the prefix (the root) as-is.
+		for i := 0; i < len(last.Vertices)-1; i++ {
+			spur := last.Vertices[i]
+			root := last.Vertices[:i+1]
+			removedEdges := map[[2]Vertex]bool{}
+			for _, p := range found {
+				if len(p.Vertices) > i+1 && samePrefix(p.Vertices, root) {
+					removedEdges[[2]Vertex{p.Vertices[i], p.Vertices[i+1]}] = true
+				}
+			}
+			removedVertices := map[Vertex]bool{}
+			for _, v := range root[:i] {
+				removedVertices[v] = true
+			}
+			spurGetNext := func(v Vertex) []Edge {
+				edges := []Edge{}
+				for _, e := range getNext(v) {
+					if !removedVertices[e.To] && !removedEdges[[2]Vertex{v, e.To}] {
+						edges = append(edges, e)
+					}
+				}
+				return edges
+			}
+			spurPath := CheapestPath(spur, isEnd, spurGetNext)
+			if !spurPath.Found {
+				continue
+			}
+			candidate := Path{
+				Vertices: append(append([]Vertex{}, root[:i]...), spurPath.Vertices...),
+				Cost:     pathCost(root, getNext) + spurPath.Cost,
+				Found:    true,
+			}
+			if !containsPath(found, candidate) && !containsPath(candidates, candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Cost < candidates[j].Cost })
+		found = append(found, candidates[0])
+		candidates = candidates[1:]
+	}
+	return found
+}
+
+// pathCost returns the cost of the path, taking the cheapest of the parallel edges.
+func pathCost(vertices []Vertex, getNext func(Vertex) []Edge) int {
+	total := 0
+	for i := 0; i < len(vertices)-1; i++ {
+		best := -1
+		for _, e := range getNext(vertices[i]) {
+			if e.To == vertices[i+1] && (best == -1 || e.Cost < best) {
+				best = e.Cost
+			}
+		}
+		total += best
+	}
+	return total
+}
+
+func samePrefix(vertices, prefix []Vertex) bool {
+	if len(vertices) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if vertices[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPath(paths []Path, p Path) bool {
+	for _, other := range paths {
+		if len(other.Vertices) == len(p.Vertices) && samePrefix(other.Vertices, p.Vertices) {
+			return true
+		}
+	}
+	return false
+}
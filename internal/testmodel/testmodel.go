@@ -0,0 +1,49 @@
+// Package testmodel is the model shared by the tests of the packages built on top of the checker.
+package testmodel
+
+import (
+	"fmt"
+
+	"github.com/jakub-m/formaggo/state"
+)
+
+// Counters is the state of the model, two counters incremented independently.
+type Counters struct {
+	A, B int
+}
+
+// Checker returns the model of the counters counting modulo the limit, with the transitions IncA and IncB and no
+// invariants.
+func Checker(limit int) state.Checker {
+	return state.Checker{
+		InitialState: Counters{},
+		NamedTransitions: []state.NamedTransition{
+			{
+				Name: "IncA",
+				Transition: state.Managed(func(sm *state.StateManager) {
+					next := sm.Curr().(Counters)
+					next.A = (next.A + 1) % limit
+					sm.AddNextState(next)
+				}),
+			},
+			{
+				Name: "IncB",
+				Transition: state.Managed(func(sm *state.StateManager) {
+					next := sm.Curr().(Counters)
+					next.B = (next.B + 1) % limit
+					sm.AddNextState(next)
+				}),
+			},
+		},
+	}
+}
+
+// ABelow returns the invariant ABelow<max>, that the counter A stays below max.
+func ABelow(max int) state.NamedInvariant {
+	return state.NamedInvariant{
+		Name: fmt.Sprintf("ABelow%d", max),
+		Inv: func(curr, next interface{}) bool {
+			return next.(Counters).A < max
+		},
+	}
+}
@@ -0,0 +1,151 @@
+// Package repl lets one play a model in the terminal: start at the initial state, pick the transitions one by one, and
+// see the invariants evaluated at each step. The transitions of the checker are called directly.
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jakub-m/formaggo/state"
+)
+
+const help = `Commands:
+  <n>          take the option n
+  l            list the options from the current state
+  t            show the trace so far
+  u            undo the last step
+  s <path>     save the trace as JSON
+  h            help
+  q            quit
+`
+
+// REPL reads the commands from In and writes to Out.
+type REPL struct {
+	Checker state.Checker
+	In      io.Reader
+	Out     io.Writer
+}
+
+// option is a state reachable from the current state with a single transition.
+type option struct {
+	transition string
+	next       interface{}
+}
+
+// Run plays the model with the standard input and output, and returns the trace of the session.
+func Run(c state.Checker) (state.Trace, error) {
+	return REPL{Checker: c, In: os.Stdin, Out: os.Stdout}.Run()
+}
+
+// Run reads the commands until "q" or the end of the input, and returns the trace of the session.
+func (r REPL) Run() (state.Trace, error) {
+	trace := state.Trace{States: []interface{}{r.Checker.InitialState}}
+	fmt.Fprint(r.Out, help)
+	fmt.Fprintf(r.Out, "initial state: %v\n", r.Checker.InitialState)
+	options := r.listOptions(trace)
+
+	scanner := bufio.NewScanner(r.In)
+	for {
+		fmt.Fprint(r.Out, "> ")
+		if !scanner.Scan() {
+			break
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch cmd := fields[0]; cmd {
+		case "q":
+			return trace, nil
+		case "h":
+			fmt.Fprint(r.Out, help)
+		case "l":
+			options = r.listOptions(trace)
+		case "t":
+			fmt.Fprint(r.Out, trace.Format(state.FormatPlain))
+		case "u":
+			if len(trace.Transitions) == 0 {
+				fmt.Fprintln(r.Out, "nothing to undo")
+				continue
+			}
+			trace.States = trace.States[:len(trace.States)-1]
+			trace.Transitions = trace.Transitions[:len(trace.Transitions)-1]
+			fmt.Fprintf(r.Out, "back at: %v\n", current(trace))
+			options = r.listOptions(trace)
+		case "s":
+			if len(fields) != 2 {
+				fmt.Fprintln(r.Out, "usage: s <path>")
+				continue
+			}
+			if err := saveTrace(fields[1], trace); err != nil {
+				fmt.Fprintf(r.Out, "cannot save: %s\n", err)
+				continue
+			}
+			fmt.Fprintf(r.Out, "saved %d steps to %s\n", len(trace.Transitions), fields[1])
+		default:
+			n, err := strconv.Atoi(cmd)
+			if err != nil || n < 1 || n > len(options) {
+				fmt.Fprintf(r.Out, "unknown command or option: %s\n", cmd)
+				continue
+			}
+			o := options[n-1]
+			prev := current(trace)
+			trace.States = append(trace.States, o.next)
+			trace.Transitions = append(trace.Transitions, o.transition)
+			r.showStep(prev, o)
+			options = r.listOptions(trace)
+		}
+	}
+	return trace, scanner.Err()
+}
+
+// listOptions prints the states reachable with each of the transitions, and returns them.
+func (r REPL) listOptions(trace state.Trace) []option {
+	curr := current(trace)
+	options := []option{}
+	for _, t := range r.Checker.NamedTransitions {
+		for _, next := range t.Transition(curr) {
+			options = append(options, option{transition: t.Name, next: next})
+		}
+	}
+	for i, o := range options {
+		if state.GetHash(o.next) == state.GetHash(curr) {
+			fmt.Fprintf(r.Out, "%d\t%s\t(no change)\n", i+1, o.transition)
+		} else {
+			fmt.Fprintf(r.Out, "%d\t%s\t%v\n", i+1, o.transition, o.next)
+		}
+	}
+	return options
+}
+
+// showStep prints the changed fields and the invariants of the step.
+func (r REPL) showStep(prev interface{}, o option) {
+	fmt.Fprintf(r.Out, "%s: %v\n", o.transition, o.next)
+	for _, c := range state.DiffStates(prev, o.next) {
+		fmt.Fprintf(r.Out, "\t%s: %s -> %s\n", c.Field, c.Before, c.After)
+	}
+	for _, inv := range r.Checker.NamedInvariants {
+		if inv.Inv(prev, o.next) {
+			fmt.Fprintf(r.Out, "invariant %s: ok\n", inv.Name)
+		} else {
+			fmt.Fprintf(r.Out, "invariant %s: VIOLATED\n", inv.Name)
+		}
+	}
+}
+
+func current(trace state.Trace) interface{} {
+	return trace.States[len(trace.States)-1]
+}
+
+func saveTrace(path string, trace state.Trace) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return state.ExportTracesToJSON(f, []state.Trace{trace})
+}
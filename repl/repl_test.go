@@ -0,0 +1,54 @@
+package repl
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jakub-m/formaggo/internal/testmodel"
+	"github.com/jakub-m/formaggo/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func getCountersChecker() state.Checker {
+	c := testmodel.Checker(3)
+	c.NamedInvariants = []state.NamedInvariant{testmodel.ABelow(2)}
+	return c
+}
+
+func TestREPL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.json")
+	in := strings.Join([]string{"2", "2", "u", "4", "9", "t", "s " + path, "q"}, "\n")
+	var out bytes.Buffer
+	trace, err := REPL{Checker: getCountersChecker(), In: strings.NewReader(in), Out: &out}.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, state.Trace{
+		States:      []interface{}{testmodel.Counters{}, testmodel.Counters{A: 1}, testmodel.Counters{A: 1, B: 1}},
+		Transitions: []string{"IncA", "IncB"},
+	}, trace)
+
+	s := out.String()
+	assert.Contains(t, s, "2\tIncA\t{1 0}\n")
+	assert.Contains(t, s, "3\tIncB\t(no change)\n")
+	assert.Contains(t, s, "invariant ABelow2: ok\n")
+	assert.Contains(t, s, "invariant ABelow2: VIOLATED\n")
+	assert.Contains(t, s, "back at: {1 0}\n")
+	assert.Contains(t, s, "unknown command or option: 9\n")
+	assert.Contains(t, s, "\tB: 0 -> 1\n")
+	assert.Contains(t, s, "saved 2 steps to ")
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var saved []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &saved))
+	assert.Equal(t, []interface{}{"IncA", "IncB"}, saved[0]["transitions"])
+}
+
+func TestREPLEndOfInput(t *testing.T) {
+	trace, err := REPL{Checker: getCountersChecker(), In: strings.NewReader("2\n"), Out: &bytes.Buffer{}}.Run()
+	assert.NoError(t, err)
+	assert.Len(t, trace.States, 2)
+}
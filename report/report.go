@@ -0,0 +1,243 @@
+// Package report writes the results of a checker run as a single HTML file. The file has no external assets, so it can
+// be attached e.g. to a code review of a model change.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jakub-m/formaggo/state"
+)
+
+// maxNeighbours limits the number of the neighbours shown around each state of a trace.
+const maxNeighbours = 8
+
+const (
+	columnWidth = 140
+	rowHeight   = 90
+	nodeRadius  = 14
+)
+
+// Report is the content of the HTML report.
+type Report struct {
+	Title string
+	// Graph is the graph explored by the run. The neighbourhood of the violation traces is taken from it.
+	Graph      state.StateGraph
+	Stats      state.Stats
+	Violations []state.Violation
+}
+
+// WriteFile writes the report to the file.
+func (r Report) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.Write(f)
+}
+
+// Write writes the report as HTML.
+func (r Report) Write(w io.Writer) error {
+	data := reportData{Report: r}
+	for i, v := range r.Violations {
+		vv, err := r.violationView(i, v)
+		if err != nil {
+			return err
+		}
+		data.ViolationViews = append(data.ViolationViews, vv)
+	}
+	return reportTemplate.Execute(w, data)
+}
+
+type reportData struct {
+	Report
+	ViolationViews []violationView
+}
+
+type violationView struct {
+	Title string
+	Table state.DiffTable
+	Graph graphView
+	Loop  int
+}
+
+// graphView is an SVG picture of a trace and the states around it. The trace is drawn left to right, the
+// predecessors of the trace states above and the successors below.
+type graphView struct {
+	ID            string
+	Width, Height int
+	Nodes         []nodeView
+	Edges         []edgeView
+}
+
+type nodeView struct {
+	ID      string
+	X, Y    int
+	Label   string
+	Title   string
+	JSON    string
+	OnTrace bool
+}
+
+type edgeView struct {
+	From, To       string
+	X1, Y1, X2, Y2 int
+	LabelX, LabelY int
+	Label          string
+	OnTrace        bool
+}
+
+func (r Report) violationView(index int, v state.Violation) (violationView, error) {
+	vv := violationView{Loop: v.LoopIndex()}
+	if v.Inv != nil {
+		vv.Title = "Violation of invariant " + v.Inv.Name
+	} else if v.Prop != nil {
+		vv.Title = "Violation of property " + v.Prop.Name
+	}
+	trace := v.Trace()
+	vv.Table = trace.DiffTable()
+	g, err := r.neighbourhood(fmt.Sprintf("v%d", index), trace)
+	if err != nil {
+		return vv, err
+	}
+	vv.Graph = g
+	return vv, nil
+}
+
+// neighbourhood lays out the trace states in a row, with their neighbours in the graph above and below.
+func (r Report) neighbourhood(id string, trace state.Trace) (graphView, error) {
+	g := graphView{ID: id}
+	nodeIDs := make(map[interface{}]string)
+	positions := make(map[string][2]int)
+	hashOf := func(s interface{}) interface{} { return state.GetHash(s) }
+	addNode := func(s interface{}, x, y int, label string, onTrace bool) (string, error) {
+		if nodeID, ok := nodeIDs[hashOf(s)]; ok {
+			return nodeID, nil
+		}
+		b, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		nodeID := fmt.Sprintf("%s-n%d", id, len(g.Nodes))
+		nodeIDs[hashOf(s)] = nodeID
+		positions[nodeID] = [2]int{x, y}
+		g.Nodes = append(g.Nodes, nodeView{ID: nodeID, X: x, Y: y, Label: label, Title: fmt.Sprint(s), JSON: string(b), OnTrace: onTrace})
+		return nodeID, nil
+	}
+	edgeSeen := make(map[[2]string]bool)
+	addEdge := func(from, to string, label string, onTrace bool) {
+		if from == to || edgeSeen[[2]string{from, to}] {
+			return
+		}
+		edgeSeen[[2]string{from, to}] = true
+		g.Edges = append(g.Edges, newEdgeView(from, to, positions[from], positions[to], label, onTrace))
+	}
+
+	rowsAbove, rowsBelow := 0, 0
+	for i, s := range trace.States {
+		if _, err := addNode(s, i*columnWidth, 0, fmt.Sprint(i), true); err != nil {
+			return g, err
+		}
+	}
+	for i := 0; i+1 < len(trace.States); i++ {
+		addEdge(nodeIDs[hashOf(trace.States[i])], nodeIDs[hashOf(trace.States[i+1])], trace.Transitions[i], true)
+	}
+	for i, s := range trace.States {
+		if !r.Graph.Contains(s) {
+			continue
+		}
+		stateID := nodeIDs[hashOf(s)]
+		above, below := 0, 0
+		for _, e := range limitEdges(r.Graph.IncomingEdges(s)) {
+			if _, ok := nodeIDs[hashOf(e.From)]; !ok {
+				above++
+			}
+			fromID, err := addNode(e.From, i*columnWidth, -above*rowHeight, "", false)
+			if err != nil {
+				return g, err
+			}
+			addEdge(fromID, stateID, strings.Join(e.Transitions, ", "), false)
+		}
+		for _, e := range limitEdges(r.Graph.OutgoingEdges(s)) {
+			if _, ok := nodeIDs[hashOf(e.To)]; !ok {
+				below++
+			}
+			toID, err := addNode(e.To, i*columnWidth, below*rowHeight, "", false)
+			if err != nil {
+				return g, err
+			}
+			addEdge(stateID, toID, strings.Join(e.Transitions, ", "), false)
+		}
+		if above > rowsAbove {
+			rowsAbove = above
+		}
+		if below > rowsBelow {
+			rowsBelow = below
+		}
+	}
+
+	// Shift everything so that the topmost row is at the margin.
+	dx, dy := columnWidth/2, rowsAbove*rowHeight+rowHeight/2
+	for i := range g.Nodes {
+		g.Nodes[i].X += dx
+		g.Nodes[i].Y += dy
+	}
+	for i := range g.Edges {
+		e := &g.Edges[i]
+		e.X1, e.X2, e.LabelX = e.X1+dx, e.X2+dx, e.LabelX+dx
+		e.Y1, e.Y2, e.LabelY = e.Y1+dy, e.Y2+dy, e.LabelY+dy
+	}
+	g.Width = len(trace.States) * columnWidth
+	g.Height = (rowsAbove + rowsBelow + 1) * rowHeight
+	return g, nil
+}
+
+// newEdgeView draws the edge as a straight line between the borders of the nodes, leaving room for the arrow head.
+func newEdgeView(from, to string, a, b [2]int, label string, onTrace bool) edgeView {
+	dx, dy := float64(b[0]-a[0]), float64(b[1]-a[1])
+	length := math.Hypot(dx, dy)
+	ux, uy := dx/length, dy/length
+	e := edgeView{
+		From:    from,
+		To:      to,
+		X1:      a[0] + int(ux*nodeRadius),
+		Y1:      a[1] + int(uy*nodeRadius),
+		X2:      b[0] - int(ux*(nodeRadius+4)),
+		Y2:      b[1] - int(uy*(nodeRadius+4)),
+		Label:   label,
+		OnTrace: onTrace,
+	}
+	e.LabelX, e.LabelY = (a[0]+b[0])/2+4, (a[1]+b[1])/2-4
+	return e
+}
+
+// limitEdges drops the stuttering steps, and keeps at most maxNeighbours edges, ordered by the hashes of the states.
+func limitEdges(edges []state.Edge) []state.Edge {
+	sort.Slice(edges, func(i, j int) bool {
+		hi, hj := state.GetHash(edges[i].From), state.GetHash(edges[j].From)
+		if hi != hj {
+			return hi < hj
+		}
+		return state.GetHash(edges[i].To) < state.GetHash(edges[j].To)
+	})
+	limited := []state.Edge{}
+	for _, e := range edges {
+		if state.GetHash(e.From) == state.GetHash(e.To) {
+			continue
+		}
+		if len(limited) == maxNeighbours {
+			break
+		}
+		limited = append(limited, e)
+	}
+	return limited
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(reportHTML))
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jakub-m/formaggo/internal/testmodel"
+	"github.com/jakub-m/formaggo/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	checker := testmodel.Checker(3)
+	checker.NamedInvariants = []state.NamedInvariant{testmodel.ABelow(2)}
+	g, stats, violation := checker.Run()
+	if !assert.NotNil(t, violation) {
+		return
+	}
+	var buf bytes.Buffer
+	err := Report{Title: "Counters", Graph: g, Stats: stats, Violations: []state.Violation{*violation}}.Write(&buf)
+	assert.NoError(t, err)
+	html := buf.String()
+	assert.Contains(t, html, "<title>Counters</title>")
+	assert.Contains(t, html, "<h3>Violation of invariant ABelow2</h3>")
+	assert.Contains(t, html, "<th>A</th>")
+	assert.Contains(t, html, "<td>IncA</td>")
+	assert.Contains(t, html, "<svg")
+	assert.Contains(t, html, `class="trace"`)
+	assert.Contains(t, html, "data-json=")
+	assert.NotContains(t, html, "src=")
+	assert.NotContains(t, html, "http")
+}
+
+func TestWriteNoViolations(t *testing.T) {
+	g, stats, _ := testmodel.Checker(3).Run()
+	var buf bytes.Buffer
+	assert.NoError(t, Report{Graph: g, Stats: stats}.Write(&buf))
+	assert.Contains(t, buf.String(), "<p>No violations.</p>")
+	assert.Contains(t, buf.String(), "<td>IncB</td><td>")
+}
@@ -0,0 +1,99 @@
+package report
+
+// reportHTML is the template of the report. The styles and the script are inline, so the file is self-contained.
+const reportHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{if .Title}}{{.Title}}{{else}}Formaggo report{{end}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; font-family: monospace; }
+th { background: #f0f0f0; }
+.unused { color: #b00; font-weight: bold; }
+.warning { color: #b60; }
+.violation { border-top: 2px solid #b00; margin-top: 2em; }
+.graph { display: flex; gap: 1em; align-items: flex-start; }
+.graph svg { border: 1px solid #ccc; background: #fcfcfc; }
+.graph pre { min-width: 20em; max-height: 30em; overflow: auto; background: #f6f6f6; padding: 0.5em; margin: 0; }
+circle { fill: #fff; stroke: #888; stroke-width: 1.5; cursor: pointer; }
+circle.trace { fill: #fde8e8; stroke: #b00; }
+circle.selected { fill: #ffe680; }
+line { stroke: #aaa; stroke-width: 1; }
+line.trace { stroke: #b00; stroke-width: 2; }
+line.active { stroke: #06c; stroke-width: 2; }
+text { font-size: 11px; pointer-events: none; }
+text.node { text-anchor: middle; dominant-baseline: central; }
+</style>
+</head>
+<body>
+<h1>{{if .Title}}{{.Title}}{{else}}Formaggo report{{end}}</h1>
+
+<h2>Statistics</h2>
+<table>
+<tr><th>distinct states</th><td>{{.Stats.DistinctStates}}</td></tr>
+<tr><th>states found</th><td>{{.Stats.StatesFound}}</td></tr>
+<tr><th>depth</th><td>{{.Stats.Depth}}</td></tr>
+<tr><th>diameter</th><td>{{.Stats.Diameter}}</td></tr>
+<tr><th>queue</th><td>{{.Stats.QueueLength}}</td></tr>
+<tr><th>states per second</th><td>{{printf "%.0f" .Stats.StatesPerSecond}}</td></tr>
+<tr><th>memory (bytes)</th><td>{{.Stats.MemoryUsed}}</td></tr>
+<tr><th>elapsed</th><td>{{.Stats.Elapsed}}</td></tr>
+</table>
+{{with .Stats.Warnings}}<h3>Warnings</h3>
+<ul>{{range .}}<li class="warning">{{.}}</li>{{end}}</ul>{{end}}
+
+<h2>Coverage</h2>
+<table>
+<tr><th>transition</th><th>new states</th><th>steps</th><th>stutters</th><th></th></tr>
+{{range .Stats.Coverage.Transitions}}<tr><td>{{.Name}}</td><td>{{.NewStates}}</td><td>{{.Steps}}</td><td>{{.Stutters}}</td><td>{{if .Unused}}<span class="unused">UNUSED</span>{{end}}</td></tr>
+{{end}}</table>
+<table>
+<tr><th>invariant</th><th>evaluations</th><th>steps</th><th></th></tr>
+{{range .Stats.Coverage.Invariants}}<tr><td>{{.Name}}</td><td>{{.Evaluations}}</td><td>{{.Steps}}</td><td>{{if .Unused}}<span class="unused">UNUSED</span>{{end}}</td></tr>
+{{end}}</table>
+
+<h2>Violations</h2>
+{{if not .ViolationViews}}<p>No violations.</p>{{end}}
+{{range .ViolationViews}}<div class="violation">
+<h3>{{.Title}}</h3>
+<table>
+<tr><th>#</th><th>transition</th>{{range .Table.Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range $i, $row := .Table.Rows}}<tr><td>{{$i}}</td><td>{{$row.Transition}}</td>{{range $row.Values}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{if ne .Loop -1}}<p>The last state loops back to state {{.Loop}}.</p>{{end}}
+<p>The trace (red) and the neighbouring states. Click a state to see it.</p>
+<div class="graph" id="{{.Graph.ID}}">
+<svg width="{{.Graph.Width}}" height="{{.Graph.Height}}">
+<defs><marker id="{{.Graph.ID}}-arrow" viewBox="0 0 10 10" refX="0" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="#888"/></marker></defs>
+{{$id := .Graph.ID}}{{range .Graph.Edges}}<line class="{{if .OnTrace}}trace{{end}}" data-from="{{.From}}" data-to="{{.To}}" x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}" marker-end="url(#{{$id}}-arrow)"/>
+<text x="{{.LabelX}}" y="{{.LabelY}}">{{.Label}}</text>
+{{end}}{{range .Graph.Nodes}}<circle class="{{if .OnTrace}}trace{{end}}" id="{{.ID}}" cx="{{.X}}" cy="{{.Y}}" r="14" data-json="{{.JSON}}"><title>{{.Title}}</title></circle>
+<text class="node" x="{{.X}}" y="{{.Y}}">{{.Label}}</text>
+{{end}}</svg>
+<pre>Click a state.</pre>
+</div>
+</div>
+{{end}}
+<script>
+document.querySelectorAll(".graph").forEach(function (graph) {
+  var pre = graph.querySelector("pre");
+  graph.querySelectorAll("circle").forEach(function (circle) {
+    circle.addEventListener("click", function () {
+      graph.querySelectorAll("circle.selected").forEach(function (c) { c.classList.remove("selected"); });
+      graph.querySelectorAll("line.active").forEach(function (l) { l.classList.remove("active"); });
+      circle.classList.add("selected");
+      graph.querySelectorAll("line").forEach(function (l) {
+        if (l.dataset.from === circle.id || l.dataset.to === circle.id) {
+          l.classList.add("active");
+        }
+      });
+      pre.textContent = circle.dataset.json;
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,289 @@
+// Package runner is the command line of a model. The main of the model only builds the checker and hands it over:
+//
+//	func main() {
+//		runner.Main(checker)
+//	}
+//
+// The flags choose how the model is checked and how the result is shown, see Run. The exit code is ExitPass,
+// ExitViolation or ExitError. Other uses of the model, e.g. searching for a state, are added as Commands.
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jakub-m/formaggo/state"
+)
+
+const (
+	// ExitPass means that no invariant or property was violated.
+	ExitPass = 0
+	// ExitViolation means that a violation was found.
+	ExitViolation = 1
+	// ExitError means that the model could not be checked, e.g. because of a wrong flag or a panic in the model.
+	ExitError = 2
+)
+
+const (
+	formatText     = "text"
+	formatColor    = "color"
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+// options are the parsed flags.
+type options struct {
+	workers     string
+	serveWorker string
+	strategy    string
+	maxDepth    int
+	simulate    int
+	simDepth    int
+	seed        int64
+	format      string
+	export      string
+	verbose     bool
+}
+
+// Command is run instead of checking the model when its name is the only argument, e.g. "solve" in `go run . solve`.
+// The log of the checker is discarded.
+type Command struct {
+	Name string
+	// Usage is a one line description shown in the help.
+	Usage string
+	// Run writes the outcome to stdout. An error exits with ExitError.
+	Run func(c state.Checker, stdout io.Writer) error
+}
+
+// Main runs the checker with the command line arguments and exits.
+func Main(c state.Checker, commands ...Command) {
+	os.Exit(Run(c, os.Args[1:], os.Stdout, os.Stderr, commands...))
+}
+
+// Run parses the flags, checks the model and writes the result to stdout. If the argument is the name of one of the
+// commands, runs the command instead. Returns the exit code.
+func Run(c state.Checker, args []string, stdout, stderr io.Writer, commands ...Command) (code int) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(stderr, "panic: %v\n", r)
+			code = ExitError
+		}
+	}()
+	if len(args) == 1 {
+		for _, cmd := range commands {
+			if cmd.Name == args[0] {
+				defer stdlog.SetOutput(stdlog.Writer())
+				stdlog.SetOutput(io.Discard)
+				if err := cmd.Run(c, stdout); err != nil {
+					fmt.Fprintln(stderr, err)
+					return ExitError
+				}
+				return ExitPass
+			}
+		}
+	}
+
+	opts, err := parseFlags(args, stderr, commands)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitPass
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	// The checker logs with the standard logger. Without -v, the log is discarded.
+	if !opts.verbose {
+		defer stdlog.SetOutput(stdlog.Writer())
+		stdlog.SetOutput(io.Discard)
+	}
+
+	if opts.serveWorker != "" {
+		if err := state.ListenAndServeWorker(c, opts.serveWorker); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+		return ExitPass
+	}
+
+	if opts.strategy == "bfs" {
+		c.Strategy = state.BFS
+	}
+	c.MaxDepth = opts.maxDepth
+	if opts.verbose {
+		c.Progress = func(s state.Stats) {
+			fmt.Fprintln(stderr, s)
+		}
+	}
+
+	var graph *state.StateGraph
+	var stats *state.Stats
+	var violation *state.Violation
+	switch {
+	case opts.simulate > 0:
+		s, v := c.Simulate(state.Simulation{Runs: opts.simulate, Depth: opts.simDepth, Seed: opts.seed})
+		stats, violation = &s, v
+	case opts.workers != "":
+		coord := state.Coordinator{Checker: c, Workers: strings.Split(opts.workers, ","), CollectGraph: opts.export != ""}
+		g, v, err := coord.Run()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+		graph, violation = &g, v
+	default:
+		g, s, v := c.Run()
+		graph, stats, violation = &g, &s, v
+	}
+
+	if opts.export != "" {
+		if err := export(*graph, opts.export); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitError
+		}
+	}
+	if err := writeResult(stdout, opts, graph, stats, violation); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	if violation != nil {
+		return ExitViolation
+	}
+	return ExitPass
+}
+
+func parseFlags(args []string, stderr io.Writer, commands []Command) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("formaggo", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: [flags]")
+		fs.PrintDefaults()
+		if len(commands) > 0 {
+			fmt.Fprintln(stderr, "Commands, run instead of checking the model:")
+			for _, cmd := range commands {
+				fmt.Fprintf(stderr, "  %s\n    \t%s\n", cmd.Name, cmd.Usage)
+			}
+		}
+	}
+	fs.StringVar(&opts.workers, "workers", "", "comma separated addresses of the workers, to check the model distributed")
+	fs.StringVar(&opts.serveWorker, "serve-worker", "", "serve as a worker at the address, e.g. localhost:7001")
+	fs.StringVar(&opts.strategy, "strategy", "dfs", "search strategy, dfs or bfs")
+	fs.IntVar(&opts.maxDepth, "max-depth", 0, "do not explore the states deeper than that, 0 for no limit")
+	fs.IntVar(&opts.simulate, "simulate", 0, "instead of exploring all the states, check that many random walks")
+	fs.IntVar(&opts.simDepth, "sim-depth", 100, "number of the steps of each random walk")
+	fs.Int64Var(&opts.seed, "seed", 1, "seed of the random walks")
+	fs.StringVar(&opts.format, "format", formatText, "output format: text, color, markdown or json")
+	fs.StringVar(&opts.export, "export", "", "export the state graph to the file, as DOT (.dot) or JSON (.json)")
+	fs.BoolVar(&opts.verbose, "v", false, "log the progress of the checker, and show the graph summary, the coverage and the warnings")
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if opts.serveWorker != "" {
+		for _, name := range []string{"workers", "strategy", "max-depth", "simulate", "sim-depth", "seed", "format", "export"} {
+			if set[name] {
+				return opts, fmt.Errorf("-%s does not apply to serving a worker", name)
+			}
+		}
+	}
+	if opts.strategy != "dfs" && opts.strategy != "bfs" {
+		return opts, fmt.Errorf("unknown strategy: %s", opts.strategy)
+	}
+	switch opts.format {
+	case formatText, formatColor, formatMarkdown, formatJSON:
+	default:
+		return opts, fmt.Errorf("unknown format: %s", opts.format)
+	}
+	if opts.export != "" {
+		if ext := filepath.Ext(opts.export); ext != ".dot" && ext != ".json" {
+			return opts, fmt.Errorf("cannot export to %s, use .dot or .json", opts.export)
+		}
+		if opts.simulate > 0 {
+			return opts, errors.New("the graph cannot be exported in the simulation mode")
+		}
+	}
+	if opts.simulate > 0 && opts.workers != "" {
+		return opts, errors.New("the simulation cannot be distributed")
+	}
+	if (set["strategy"] || set["max-depth"]) && opts.simulate > 0 {
+		return opts, errors.New("the search strategy and the depth limit do not apply to the simulation")
+	}
+	if (set["strategy"] || set["max-depth"]) && opts.workers != "" {
+		return opts, errors.New("the search strategy and the depth limit do not apply to the distributed checking")
+	}
+	if (set["seed"] || set["sim-depth"]) && opts.simulate == 0 {
+		return opts, errors.New("the seed and the depth of the random walks apply only to the simulation")
+	}
+	return opts, nil
+}
+
+func export(g state.StateGraph, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if filepath.Ext(path) == ".json" {
+		return g.ExportToJSON(f)
+	}
+	return g.ExportToDot(f)
+}
+
+type jsonResult struct {
+	Result    string           `json:"result"`
+	Stats     *state.Stats     `json:"stats,omitempty"`
+	Violation *state.Violation `json:"violation,omitempty"`
+}
+
+// writeResult writes the stats (if any) and the violation. The stats are nil for a distributed run, the graph is nil
+// for a simulation.
+func writeResult(w io.Writer, opts options, graph *state.StateGraph, stats *state.Stats, violation *state.Violation) error {
+	if opts.format == formatJSON {
+		r := jsonResult{Result: "pass", Stats: stats, Violation: violation}
+		if violation != nil {
+			r.Result = "violation"
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		return enc.Encode(r)
+	}
+
+	if stats != nil {
+		fmt.Fprintln(w, stats)
+		if opts.verbose {
+			if graph != nil {
+				// Computed only here, the SCCs of a large graph take a while.
+				fmt.Fprint(w, graph.Summary())
+			}
+			fmt.Fprint(w, stats.Coverage)
+			for _, warning := range stats.Warnings {
+				fmt.Fprintf(w, "WARNING: %s\n", warning)
+			}
+		}
+	}
+	if violation == nil {
+		fmt.Fprintln(w, "PASS")
+		return nil
+	}
+	traceFormat := state.FormatPlain
+	switch opts.format {
+	case formatColor:
+		traceFormat = state.FormatColor
+	case formatMarkdown:
+		traceFormat = state.FormatMarkdown
+	}
+	fmt.Fprint(w, violation.Format(traceFormat))
+	fmt.Fprintln(w, "VIOLATION")
+	return nil
+}
@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jakub-m/formaggo/internal/testmodel"
+	"github.com/jakub-m/formaggo/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func getCountersChecker(withInvariant bool) state.Checker {
+	c := testmodel.Checker(3)
+	if withInvariant {
+		c.NamedInvariants = []state.NamedInvariant{testmodel.ABelow(2)}
+	}
+	return c
+}
+
+func run(c state.Checker, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(c, args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunPass(t *testing.T) {
+	code, out, _ := run(getCountersChecker(false), "-strategy", "bfs", "-v")
+	assert.Equal(t, ExitPass, code)
+	assert.Contains(t, out, "distinct: 9")
+	assert.Contains(t, out, "TRANSITION")
+	assert.Contains(t, out, "SCCs: 1, largest SCC: 9\n")
+	assert.Contains(t, out, "PASS\n")
+}
+
+func TestRunViolation(t *testing.T) {
+	code, out, _ := run(getCountersChecker(true))
+	assert.Equal(t, ExitViolation, code)
+	assert.Contains(t, out, "Violation of invariant: ABelow2\n")
+	assert.Contains(t, out, "VIOLATION\n")
+}
+
+func TestRunJSON(t *testing.T) {
+	code, out, _ := run(getCountersChecker(true), "-format", "json")
+	assert.Equal(t, ExitViolation, code)
+	var r map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(out), &r))
+	assert.Equal(t, "violation", r["result"])
+	assert.Equal(t, "ABelow2", r["violation"].(map[string]interface{})["name"])
+}
+
+func TestRunSimulate(t *testing.T) {
+	code, out, _ := run(getCountersChecker(true), "-simulate", "10", "-sim-depth", "20", "-seed", "3")
+	assert.Equal(t, ExitViolation, code)
+	assert.Contains(t, out, "ABelow2")
+}
+
+func TestRunMaxDepth(t *testing.T) {
+	code, out, _ := run(getCountersChecker(false), "-max-depth", "1", "-format", "json")
+	assert.Equal(t, ExitPass, code)
+	var r struct{ Stats state.Stats }
+	assert.NoError(t, json.Unmarshal([]byte(out), &r))
+	assert.Equal(t, 3, r.Stats.DistinctStates)
+	assert.True(t, r.Stats.DepthLimitReached)
+}
+
+func TestRunExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.json")
+	code, _, _ := run(getCountersChecker(false), "-export", path)
+	assert.Equal(t, ExitPass, code)
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"transitions"`)
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-strategy", "random"},
+		{"-format", "xml"},
+		{"-export", "graph.png"},
+		{"-simulate", "1", "-workers", "localhost:1"},
+		{"-simulate", "1", "-strategy", "bfs"},
+		{"-max-depth", "2", "-workers", "localhost:1"},
+		{"-seed", "2"},
+		{"-sim-depth", "2"},
+		{"-no-such-flag"},
+		{"extra"},
+		{"-serve-worker", "localhost:1", "-workers", "localhost:2"},
+		{"-serve-worker", "localhost:1", "-simulate", "1"},
+		{"-serve-worker", "localhost:1", "-format", "json"},
+		{"-serve-worker", "localhost:1", "-export", "graph.dot"},
+	} {
+		code, _, _ := run(getCountersChecker(false), args...)
+		assert.Equal(t, ExitError, code, args)
+	}
+
+	c := getCountersChecker(false)
+	c.NamedTransitions[0].Transition = func(interface{}) []interface{} { panic("boom") }
+	code, _, stderr := run(c)
+	assert.Equal(t, ExitError, code)
+	assert.Contains(t, stderr, "panic: boom")
+}
+
+func TestRunVerbose(t *testing.T) {
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+
+	run(getCountersChecker(false))
+	assert.Empty(t, logged.String())
+	run(getCountersChecker(false), "-v")
+	assert.Contains(t, logged.String(), "Start checker")
+}
+
+func TestRunCommand(t *testing.T) {
+	states := Command{
+		Name:  "states",
+		Usage: "print the number of the states",
+		Run: func(c state.Checker, stdout io.Writer) error {
+			g, _, _ := c.Run()
+			_, err := fmt.Fprintf(stdout, "%d states\n", g.NumStates())
+			return err
+		},
+	}
+	failing := Command{
+		Name: "fail",
+		Run:  func(state.Checker, io.Writer) error { return errors.New("failed") },
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run(getCountersChecker(false), []string{"states"}, &stdout, &stderr, states, failing)
+	assert.Equal(t, ExitPass, code)
+	assert.Equal(t, "9 states\n", stdout.String())
+
+	code = Run(getCountersChecker(false), []string{"fail"}, &stdout, &stderr, states, failing)
+	assert.Equal(t, ExitError, code)
+	assert.Equal(t, "failed\n", stderr.String())
+
+	stderr.Reset()
+	code = Run(getCountersChecker(false), []string{"-h"}, &stdout, &stderr, states, failing)
+	assert.Equal(t, ExitPass, code)
+	assert.Contains(t, stderr.String(), "  states\n    \tprint the number of the states\n")
+
+	code = Run(getCountersChecker(false), []string{"states", "extra"}, &stdout, &stderr, states, failing)
+	assert.Equal(t, ExitError, code)
+}
@@ -0,0 +1,52 @@
+package shortestpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getNextFromMap(m map[Vertex][]Edge) func(Vertex) []Edge {
+	return func(v Vertex) []Edge {
+		return m[v]
+	}
+}
+
+func TestFind(t *testing.T) {
+	m := map[Vertex][]Vertex{
+		0: {1, 2},
+		1: {3},
+		2: {4},
+		4: {3},
+	}
+	getNext := func(v Vertex) []Vertex { return m[v] }
+	assert.Equal(t, []Vertex{0, 1, 3}, Find(0, 3, getNext))
+	assert.Equal(t, []Vertex{0}, Find(0, 0, getNext))
+	assert.Equal(t, []Vertex{}, Find(3, 0, getNext))
+}
+
+func TestFindWeighted(t *testing.T) {
+	getNext := getNextFromMap(map[Vertex][]Edge{
+		0: {{1, 10}, {2, 1}},
+		1: {{3, 1}},
+		2: {{4, 1}},
+		4: {{3, 1}},
+	})
+	assert.Equal(t, []Vertex{0, 2, 4, 3}, FindWeighted(0, 3, getNext))
+}
+
+func TestFindFirst(t *testing.T) {
+	getNext := getNextFromMap(map[Vertex][]Edge{
+		0: {{1, 5}, {2, 1}},
+		2: {{3, 1}},
+	})
+	isEnd := func(v Vertex) bool { return v == 1 || v == 3 }
+	assert.Equal(t, []Vertex{0, 2, 3}, FindFirst(0, isEnd, getNext))
+}
+
+func TestFindNegativeCost(t *testing.T) {
+	getNext := getNextFromMap(map[Vertex][]Edge{
+		0: {{1, -1}},
+	})
+	assert.Panics(t, func() { FindWeighted(0, 1, getNext) })
+}
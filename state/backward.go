@@ -0,0 +1,146 @@
+package state
+
+import (
+	"fmt"
+	"math"
+)
+
+// BackwardAnalysis tells how the bad states can be reached. See StateGraph.BackwardReachable.
+type BackwardAnalysis struct {
+	// Bad are the states meeting the bad condition.
+	Bad []interface{}
+	// CanReachBad are the states from which there is a path to a bad state, including the bad states.
+	CanReachBad []interface{}
+	// Unavoidable are the states from which every path leads to a bad state, including the bad states. The stuttering
+	// steps are ignored, as in the temporal properties. A deadlock that is not bad is avoidable.
+	Unavoidable []interface{}
+	// PointsOfNoReturn are the unavoidable states, that are not bad, and that are entered from an avoidable state.
+	// These are the steps after which the bad state cannot be avoided anymore.
+	PointsOfNoReturn []interface{}
+	// ShortestPaths is the number of the shortest paths from the initial state to each of the bad states, summed up.
+	// The paths do not pass through other bad states. Saturates at math.MaxUint64.
+	ShortestPaths uint64
+}
+
+func (a BackwardAnalysis) String() string {
+	return fmt.Sprintf("bad: %d, can reach bad: %d, unavoidable: %d, points of no return: %d, shortest paths to bad: %d",
+		len(a.Bad), len(a.CanReachBad), len(a.Unavoidable), len(a.PointsOfNoReturn), a.ShortestPaths)
+}
+
+// BackwardReachable computes which states lead to the states meeting the bad condition, using the reverse index of
+// the graph.
+func (g StateGraph) BackwardReachable(bad StateCondition) BackwardAnalysis {
+	predecessors := g.hashPredecessors()
+	badHashes := []stateHash{}
+	isBad := make(map[stateHash]bool)
+	for _, h := range g.sortedHashes() {
+		if bad(g.hashToState[h]) {
+			badHashes = append(badHashes, h)
+			isBad[h] = true
+		}
+	}
+
+	// Backward BFS from all the bad states at once.
+	canReach := make(map[stateHash]bool)
+	canReachHashes := []stateHash{}
+	for _, h := range badHashes {
+		canReach[h] = true
+		canReachHashes = append(canReachHashes, h)
+	}
+	for i := 0; i < len(canReachHashes); i++ {
+		for _, prev := range predecessors[canReachHashes[i]] {
+			if !canReach[prev] {
+				canReach[prev] = true
+				canReachHashes = append(canReachHashes, prev)
+			}
+		}
+	}
+
+	// A state is unavoidable when all its successors are. Count down the successors that are not known to be
+	// unavoidable yet, and propagate backwards when the count drops to zero.
+	remaining := make(map[stateHash]int)
+	for h, nextHashes := range g.hashGraph {
+		for _, next := range nextHashes {
+			if next != h {
+				remaining[h]++
+			}
+		}
+	}
+	unavoidable := make(map[stateHash]bool)
+	unavoidableHashes := []stateHash{}
+	for _, h := range badHashes {
+		unavoidable[h] = true
+		unavoidableHashes = append(unavoidableHashes, h)
+	}
+	for i := 0; i < len(unavoidableHashes); i++ {
+		h := unavoidableHashes[i]
+		for _, prev := range predecessors[h] {
+			if prev == h || unavoidable[prev] {
+				continue
+			}
+			remaining[prev]--
+			if remaining[prev] == 0 {
+				unavoidable[prev] = true
+				unavoidableHashes = append(unavoidableHashes, prev)
+			}
+		}
+	}
+
+	pointsOfNoReturn := []stateHash{}
+	for _, h := range unavoidableHashes {
+		if isBad[h] {
+			continue
+		}
+		for _, prev := range predecessors[h] {
+			if !unavoidable[prev] {
+				pointsOfNoReturn = append(pointsOfNoReturn, h)
+				break
+			}
+		}
+	}
+
+	return BackwardAnalysis{
+		Bad:              g.hashesToStates(badHashes),
+		CanReachBad:      g.hashesToStates(canReachHashes),
+		Unavoidable:      g.hashesToStates(unavoidableHashes),
+		PointsOfNoReturn: g.hashesToStates(pointsOfNoReturn),
+		ShortestPaths:    g.countShortestPaths(g.initialHash, isBad),
+	}
+}
+
+// countShortestPaths counts the shortest paths from start to each of the end states, not passing through other end
+// states.
+func (g StateGraph) countShortestPaths(start stateHash, isEnd map[stateHash]bool) uint64 {
+	dist := map[stateHash]int{start: 0}
+	counts := map[stateHash]uint64{start: 1}
+	level := []stateHash{start}
+	total := uint64(0)
+	for len(level) > 0 {
+		nextLevel := []stateHash{}
+		for _, h := range level {
+			if isEnd[h] {
+				total = saturatingAdd(total, counts[h])
+				continue
+			}
+			for _, next := range g.hashGraph[h] {
+				d, ok := dist[next]
+				if !ok {
+					dist[next] = dist[h] + 1
+					nextLevel = append(nextLevel, next)
+				} else if d != dist[h]+1 {
+					continue
+				}
+				counts[next] = saturatingAdd(counts[next], counts[h])
+			}
+		}
+		level = nextLevel
+	}
+	return total
+}
+
+func saturatingAdd(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+	return a + b
+}
@@ -0,0 +1,48 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackwardReachable(t *testing.T) {
+	g, _, _ := getRingChecker().Run()
+	a := g.BackwardReachable(StateEquals(4))
+	assert.Equal(t, []interface{}{4}, a.Bad)
+	assert.ElementsMatch(t, []interface{}{0, 1, 2, 3, 4}, a.CanReachBad)
+	assert.ElementsMatch(t, []interface{}{3, 4}, a.Unavoidable)
+	assert.Equal(t, []interface{}{3}, a.PointsOfNoReturn)
+	assert.Equal(t, uint64(1), a.ShortestPaths)
+
+	a = g.BackwardReachable(StateEquals(10))
+	assert.Empty(t, a.Bad)
+	assert.Empty(t, a.CanReachBad)
+	assert.Equal(t, uint64(0), a.ShortestPaths)
+}
+
+func TestBackwardReachableDiamond(t *testing.T) {
+	// 0 -> 1|2 -> 3 -> 4, and 2 -> 5 which is a deadlock.
+	g := StateGraph{
+		hashGraph: map[stateHash][]stateHash{
+			0: {1, 2},
+			1: {3},
+			2: {3, 5},
+			3: {4},
+			4: {},
+			5: {5},
+		},
+		hashToState: make(map[stateHash]interface{}),
+		initialHash: 0,
+	}
+	for h := range g.hashGraph {
+		g.hashToState[h] = h
+	}
+	isBad := func(s interface{}) bool { return s == stateHash(3) || s == stateHash(4) }
+	a := g.BackwardReachable(isBad)
+	assert.ElementsMatch(t, []interface{}{stateHash(3), stateHash(4)}, a.Bad)
+	assert.ElementsMatch(t, []interface{}{stateHash(0), stateHash(1), stateHash(2), stateHash(3), stateHash(4)}, a.CanReachBad)
+	assert.ElementsMatch(t, []interface{}{stateHash(1), stateHash(3), stateHash(4)}, a.Unavoidable)
+	assert.Equal(t, []interface{}{stateHash(1)}, a.PointsOfNoReturn)
+	assert.Equal(t, uint64(2), a.ShortestPaths) // 0,1,3 and 0,2,3; 4 is behind 3
+}
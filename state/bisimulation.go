@@ -0,0 +1,163 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// QuotientState is a state of a quotient graph. It stands for a class of bisimilar states of the original graph.
+type QuotientState struct {
+	// Class is the index of Quotient.Classes.
+	Class int
+	// Observation is the observable label shared by all the states of the class.
+	Observation interface{}
+	// Size is the number of the states in the class.
+	Size int
+}
+
+func (s QuotientState) String() string {
+	return fmt.Sprintf("#%d %v (%d states)", s.Class, s.Observation, s.Size)
+}
+
+// Quotient is a smaller graph, where the bisimilar states of the original graph are merged. See
+// StateGraph.Bisimulation.
+type Quotient struct {
+	// Graph has QuotientState states, and edges between the classes labelled with all the transitions of the merged
+	// edges.
+	Graph StateGraph
+	// Classes are the states of the original graph, merged into each of the quotient states.
+	Classes     [][]interface{}
+	classOf     map[stateHash]int
+	classHashes []stateHash
+}
+
+// ClassOf returns the quotient state with the given state of the original graph.
+func (q Quotient) ClassOf(s interface{}) (QuotientState, bool) {
+	class, ok := q.classOf[GetHash(s)]
+	if !ok {
+		return QuotientState{}, false
+	}
+	return q.Graph.hashToState[q.classHashes[class]].(QuotientState), true
+}
+
+// Bisimulation merges the states that have the same observation and whose futures are equivalent, i.e. the states
+// that are bisimilar: for each step of one of the states, the other can make a step to an equivalent state. The
+// observation is a function returning a hashable label of a state, e.g. some of its fields. The stuttering steps are
+// kept as self-loops.
+func (g StateGraph) Bisimulation(observe func(interface{}) interface{}) Quotient {
+	hashes := g.sortedHashes()
+	// Start with the partition by the observation, then refine it until the partition is stable.
+	blockOf := make(map[stateHash]int)
+	numBlocks := assignBlocks(hashes, blockOf, func(h stateHash) string {
+		return fmt.Sprint(GetHash(observe(g.hashToState[h])))
+	})
+	for {
+		previous := make(map[stateHash]int)
+		for h, b := range blockOf {
+			previous[h] = b
+		}
+		n := assignBlocks(hashes, blockOf, func(h stateHash) string {
+			return g.blockSignature(h, previous)
+		})
+		if n == numBlocks {
+			break
+		}
+		numBlocks = n
+	}
+
+	return g.buildQuotient(hashes, blockOf, numBlocks, observe)
+}
+
+// assignBlocks numbers the states by their keys, in order of the first appearance, and returns the number of blocks.
+func assignBlocks(hashes []stateHash, blockOf map[stateHash]int, key func(stateHash) string) int {
+	blocks := make(map[string]int)
+	for _, h := range hashes {
+		k := key(h)
+		b, ok := blocks[k]
+		if !ok {
+			b = len(blocks)
+			blocks[k] = b
+		}
+		blockOf[h] = b
+	}
+	return len(blocks)
+}
+
+// blockSignature is the block of the state together with the set of the blocks of its successors.
+func (g StateGraph) blockSignature(h stateHash, blockOf map[stateHash]int) string {
+	nextBlocks := []int{}
+	seen := make(map[int]bool)
+	for _, next := range g.hashGraph[h] {
+		if b := blockOf[next]; !seen[b] {
+			seen[b] = true
+			nextBlocks = append(nextBlocks, b)
+		}
+	}
+	sort.Ints(nextBlocks)
+	parts := []string{}
+	for _, b := range nextBlocks {
+		parts = append(parts, fmt.Sprint(b))
+	}
+	return fmt.Sprintf("%d:%s", blockOf[h], strings.Join(parts, ","))
+}
+
+func (g StateGraph) buildQuotient(hashes []stateHash, blockOf map[stateHash]int, numBlocks int, observe func(interface{}) interface{}) Quotient {
+	q := Quotient{
+		Graph: StateGraph{
+			hashGraph:       make(map[stateHash][]stateHash),
+			hashToState:     make(map[stateHash]interface{}),
+			edgeTransitions: make(map[hashEdge][]int),
+			transitionNames: g.transitionNames,
+			index:           &graphIndex{},
+		},
+		Classes: make([][]interface{}, numBlocks),
+		classOf: blockOf,
+	}
+	for _, h := range hashes {
+		q.Classes[blockOf[h]] = append(q.Classes[blockOf[h]], g.hashToState[h])
+	}
+	classHashes := make([]stateHash, numBlocks)
+	q.classHashes = classHashes
+	for class, members := range q.Classes {
+		qs := QuotientState{Class: class, Observation: observe(members[0]), Size: len(members)}
+		classHashes[class] = GetHash(qs)
+		q.Graph.hashToState[classHashes[class]] = qs
+	}
+	if class, ok := blockOf[g.initialHash]; ok {
+		q.Graph.initialHash = classHashes[class]
+	}
+
+	for _, h := range hashes {
+		from := classHashes[blockOf[h]]
+		if _, ok := q.Graph.hashGraph[from]; !ok {
+			q.Graph.hashGraph[from] = []stateHash{}
+		}
+		for _, next := range g.hashGraph[h] {
+			to := classHashes[blockOf[next]]
+			e := hashEdge{from, to}
+			if _, ok := q.Graph.edgeTransitions[e]; !ok {
+				q.Graph.hashGraph[from] = append(q.Graph.hashGraph[from], to)
+				q.Graph.edgeTransitions[e] = []int{}
+			}
+			for _, t := range g.edgeTransitions[hashEdge{h, next}] {
+				if !containsInt(q.Graph.edgeTransitions[e], t) {
+					q.Graph.edgeTransitions[e] = append(q.Graph.edgeTransitions[e], t)
+				}
+			}
+		}
+	}
+	for _, transitions := range q.Graph.edgeTransitions {
+		sort.Ints(transitions)
+	}
+	return q
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBisimulation(t *testing.T) {
+	// Counters modulo 4, observed as the parity of A+B. All the states with the same parity have the same future.
+	g, _, _ := getCountersChecker(4).Run()
+	q := g.Bisimulation(func(s interface{}) interface{} {
+		c := s.(counters)
+		return (c.A + c.B) % 2
+	})
+	assert.Equal(t, 2, q.Graph.NumStates())
+	assert.Len(t, q.Classes, 2)
+	assert.Len(t, q.Classes[0], 8)
+	initial := q.Graph.InitialState().(QuotientState)
+	assert.Equal(t, QuotientState{Class: initial.Class, Observation: 0, Size: 8}, initial)
+	other, ok := q.ClassOf(counters{A: 1})
+	assert.True(t, ok)
+	assert.Equal(t, 1, other.Observation)
+	assert.ElementsMatch(t, []Edge{
+		{From: initial, To: initial, Transitions: []string{"IncA", "IncB"}},
+		{From: initial, To: other, Transitions: []string{"IncA", "IncB"}},
+	}, q.Graph.OutgoingEdges(initial))
+}
+
+func TestBisimulationSplitsByFuture(t *testing.T) {
+	// 0 -> 1 -> 2 -> 0 and 2 -> 3 -> 4.
+	g, _, _ := getRingChecker().Run()
+
+	// Nothing observable, all the states look the same.
+	q := g.Bisimulation(func(interface{}) interface{} { return "same" })
+	assert.Equal(t, 1, q.Graph.NumStates())
+
+	// When only 4 is observable, the states differ by how soon they can reach 4.
+	q = g.Bisimulation(func(s interface{}) interface{} { return s == 4 })
+	assert.Equal(t, 5, q.Graph.NumStates())
+	c0, _ := q.ClassOf(0)
+	c1, _ := q.ClassOf(1)
+	assert.NotEqual(t, c0, c1)
+	_, ok := q.ClassOf(10)
+	assert.False(t, ok)
+}
@@ -0,0 +1,128 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+)
+
+// Coverage tells how often the transitions and the invariants did something during the run. A transition that never
+// changes the state, or an invariant that never sees a state change, usually means a typo in a guard, and the model is
+// then checked vacuously.
+type Coverage struct {
+	Transitions []TransitionCoverage
+	Invariants  []InvariantCoverage
+}
+
+type TransitionCoverage struct {
+	Name string
+	// NewStates is the number of times the transition led to a state not seen before.
+	NewStates int
+	// Steps is the number of times the transition led to a state different than the current one.
+	Steps int
+	// Stutters is the number of times the transition led back to the current state.
+	Stutters int
+}
+
+// Unused is true if the transition never changed the state.
+func (t TransitionCoverage) Unused() bool {
+	return t.Steps == 0
+}
+
+type InvariantCoverage struct {
+	Name string
+	// Evaluations is the number of times the invariant was evaluated.
+	Evaluations int
+	// Steps is the number of evaluations where the next state was different than the current one.
+	Steps int
+}
+
+// Unused is true if the invariant never was evaluated on a state change.
+func (i InvariantCoverage) Unused() bool {
+	return i.Steps == 0
+}
+
+func newCoverage(c Checker) Coverage {
+	cov := Coverage{}
+	for _, t := range c.NamedTransitions {
+		cov.Transitions = append(cov.Transitions, TransitionCoverage{Name: t.Name})
+	}
+	for _, inv := range c.NamedInvariants {
+		cov.Invariants = append(cov.Invariants, InvariantCoverage{Name: inv.Name})
+	}
+	return cov
+}
+
+// Unused returns names of the transitions and the invariants that never mattered.
+func (c Coverage) Unused() []string {
+	names := []string{}
+	for _, t := range c.Transitions {
+		if t.Unused() {
+			names = append(names, t.Name)
+		}
+	}
+	for _, inv := range c.Invariants {
+		if inv.Unused() {
+			names = append(names, inv.Name)
+		}
+	}
+	return names
+}
+
+// String returns the coverage as a printable table.
+func (c Coverage) String() string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TRANSITION\tNEW STATES\tSTEPS\tSTUTTERS\t")
+	for _, t := range c.Transitions {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", t.Name, t.NewStates, t.Steps, t.Stutters, unusedMark(t.Unused()))
+	}
+	fmt.Fprintln(w, "\t\t\t\t")
+	fmt.Fprintln(w, "INVARIANT\tEVALUATIONS\tSTEPS\t\t")
+	for _, inv := range c.Invariants {
+		fmt.Fprintf(w, "%s\t%d\t%d\t\t%s\n", inv.Name, inv.Evaluations, inv.Steps, unusedMark(inv.Unused()))
+	}
+	w.Flush()
+	return b.String()
+}
+
+func unusedMark(unused bool) string {
+	if unused {
+		return "UNUSED"
+	}
+	return ""
+}
+
+// copy returns a deep copy, so the coverage reported with Progress is not modified later on.
+func (c Coverage) copy() Coverage {
+	return Coverage{
+		Transitions: append([]TransitionCoverage{}, c.Transitions...),
+		Invariants:  append([]InvariantCoverage{}, c.Invariants...),
+	}
+}
+
+// countTransitions counts a step from the current state to a next state, made by the transitions of given indices.
+func (c *Coverage) countTransitions(transitions []int, isStutter, isNew bool) {
+	for _, i := range transitions {
+		t := &c.Transitions[i]
+		if isStutter {
+			t.Stutters++
+			continue
+		}
+		t.Steps++
+		if isNew {
+			t.NewStates++
+		}
+	}
+}
+
+// countInvariants counts evaluation of the first n invariants.
+func (c *Coverage) countInvariants(n int, isStutter bool) {
+	for i := 0; i < n; i++ {
+		inv := &c.Invariants[i]
+		inv.Evaluations++
+		if !isStutter {
+			inv.Steps++
+		}
+	}
+}
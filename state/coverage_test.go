@@ -0,0 +1,43 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverage(t *testing.T) {
+	checker := getCountersChecker(3)
+	checker.NamedTransitions = append(checker.NamedTransitions, NamedTransition{
+		Name: "NeverFires",
+		Transition: Managed(func(sm *StateManager) {
+			next := sm.Curr().(counters)
+			if next.A > 100 {
+				next.A = 0
+				sm.AddNextState(next)
+			}
+		}),
+	})
+	checker.NamedInvariants = []NamedInvariant{
+		{
+			Name: "AlwaysTrue",
+			Inv:  func(curr, next interface{}) bool { return true },
+		},
+	}
+	_, stats, violation := checker.Run()
+	assert.Nil(t, violation)
+
+	cov := stats.Coverage
+	assert.Len(t, cov.Transitions, 3)
+	// Which of IncA and IncB finds a state first depends on the order of exploration.
+	assert.Equal(t, 8, cov.Transitions[0].NewStates+cov.Transitions[1].NewStates)
+	assert.Equal(t, 9, cov.Transitions[0].Steps)
+	assert.Equal(t, 9, cov.Transitions[1].Steps)
+	assert.Equal(t, 9, cov.Transitions[0].Stutters) // Managed adds the stuttering step.
+	assert.Equal(t, TransitionCoverage{Name: "NeverFires", Stutters: 9}, cov.Transitions[2])
+	assert.Equal(t, []InvariantCoverage{
+		{Name: "AlwaysTrue", Evaluations: 27, Steps: 18},
+	}, cov.Invariants)
+	assert.Equal(t, []string{"NeverFires"}, cov.Unused())
+	assert.Contains(t, cov.String(), "UNUSED")
+}
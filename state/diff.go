@@ -0,0 +1,248 @@
+package state
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// TraceFormat is the format of Trace.Format.
+type TraceFormat int
+
+const (
+	// FormatPlain is plain text.
+	FormatPlain TraceFormat = iota
+	// FormatColor is text with the terminal colour codes, the old values red and the new values green.
+	FormatColor
+	// FormatMarkdown is a Markdown table with a row per state and a column per field.
+	FormatMarkdown
+)
+
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorReset = "\x1b[0m"
+	// missingField is the value of a field absent in a state, e.g. an element beyond the end of a slice.
+	missingField = "-"
+)
+
+// FieldChange is a field with different values in two states.
+type FieldChange struct {
+	// Field is the path to the field, e.g. "Version[1]" or "Server.Name".
+	Field         string
+	Before, After string
+}
+
+// DiffStates returns the fields that differ between the states. The states are walked by reflection down to the
+// primitive values: struct fields, elements of arrays and slices, map values and pointed values.
+func DiffStates(a, b interface{}) []FieldChange {
+	namesA, valuesA := flattenState(a)
+	namesB, valuesB := flattenState(b)
+	changes := []FieldChange{}
+	for _, name := range mergeFieldNames(namesA, namesB) {
+		before, ok := valuesA[name]
+		if !ok {
+			before = missingField
+		}
+		after, ok := valuesB[name]
+		if !ok {
+			after = missingField
+		}
+		if before != after {
+			changes = append(changes, FieldChange{Field: name, Before: before, After: after})
+		}
+	}
+	return changes
+}
+
+// Format shows the first state of the trace in full, and then only the fields that changed at each step.
+func (t Trace) Format(f TraceFormat) string {
+	if f == FormatMarkdown {
+		return t.formatMarkdown()
+	}
+	if len(t.States) == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("0\t%v\n", t.States[0])
+	for i := 0; i+1 < len(t.States); i++ {
+		s += fmt.Sprintf("%d->%d\t%s\n", i, i+1, t.transitionName(i))
+		changes := DiffStates(t.States[i], t.States[i+1])
+		if len(changes) == 0 {
+			s += "\t(no change)\n"
+		}
+		for _, c := range changes {
+			if f == FormatColor {
+				s += fmt.Sprintf("\t%s: %s%s%s -> %s%s%s\n", c.Field, colorRed, c.Before, colorReset, colorGreen, c.After, colorReset)
+			} else {
+				s += fmt.Sprintf("\t%s: %s -> %s\n", c.Field, c.Before, c.After)
+			}
+		}
+	}
+	if t.Cost != 0 {
+		s += fmt.Sprintf("cost: %d\n", t.Cost)
+	}
+	return s
+}
+
+// DiffTable is a trace as a table with a row per state and a column per field that changes along the trace. The
+// first row has all the values, the following rows only the changed ones.
+type DiffTable struct {
+	Columns []string
+	Rows    []DiffRow
+}
+
+// DiffRow is a state of the DiffTable.
+type DiffRow struct {
+	// Transition is the name of the transition leading to the state, empty for the first state.
+	Transition string
+	// Values are the values of the columns, empty where the value did not change.
+	Values []string
+}
+
+// DiffTable returns the trace as a table of the changed fields.
+func (t Trace) DiffTable() DiffTable {
+	changed := make(map[string]bool)
+	for i := 0; i+1 < len(t.States); i++ {
+		for _, c := range DiffStates(t.States[i], t.States[i+1]) {
+			changed[c.Field] = true
+		}
+	}
+	allNames := []string{}
+	values := []map[string]string{}
+	for _, st := range t.States {
+		names, v := flattenState(st)
+		allNames = mergeFieldNames(allNames, names)
+		values = append(values, v)
+	}
+	table := DiffTable{Columns: []string{}, Rows: []DiffRow{}}
+	for _, name := range allNames {
+		if changed[name] {
+			table.Columns = append(table.Columns, name)
+		}
+	}
+	valueAt := func(i int, c string) string {
+		if v, ok := values[i][c]; ok {
+			return v
+		}
+		return missingField
+	}
+	for i := range t.States {
+		row := DiffRow{}
+		if i > 0 {
+			row.Transition = t.transitionName(i - 1)
+		}
+		for _, c := range table.Columns {
+			v := valueAt(i, c)
+			if i > 0 && valueAt(i-1, c) == v {
+				v = ""
+			}
+			row.Values = append(row.Values, v)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+func (t Trace) formatMarkdown() string {
+	if len(t.States) == 0 {
+		return ""
+	}
+	table := t.DiffTable()
+	s := "| # | transition |"
+	separator := "|---|---|"
+	for _, c := range table.Columns {
+		s += fmt.Sprintf(" %s |", escapeMarkdown(c))
+		separator += "---|"
+	}
+	s += "\n" + separator + "\n"
+	for i, row := range table.Rows {
+		s += fmt.Sprintf("| %d | %s |", i, escapeMarkdown(row.Transition))
+		for _, v := range row.Values {
+			s += fmt.Sprintf(" %s |", escapeMarkdown(v))
+		}
+		s += "\n"
+	}
+	return s
+}
+
+func (t Trace) transitionName(i int) string {
+	if i < len(t.Transitions) {
+		return t.Transitions[i]
+	}
+	return ""
+}
+
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// flattenState returns the paths to the primitive values of the state in order, and the printed values by path.
+func flattenState(s interface{}) ([]string, map[string]string) {
+	names := []string{}
+	values := make(map[string]string)
+	var walk func(v reflect.Value, name string)
+	walk = func(v reflect.Value, name string) {
+		switch v.Kind() {
+		case reflect.Struct:
+			for i := 0; i < v.NumField(); i++ {
+				walk(v.Field(i), joinFieldName(name, v.Type().Field(i).Name))
+			}
+			return
+		case reflect.Array, reflect.Slice:
+			if v.Kind() == reflect.Slice && v.IsNil() {
+				break
+			}
+			for i := 0; i < v.Len(); i++ {
+				walk(v.Index(i), fmt.Sprintf("%s[%d]", name, i))
+			}
+			return
+		case reflect.Map:
+			if v.IsNil() {
+				break
+			}
+			keys := v.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+			for _, k := range keys {
+				walk(v.MapIndex(k), fmt.Sprintf("%s[%v]", name, k))
+			}
+			return
+		case reflect.Invalid:
+			v = reflect.ValueOf("nil")
+		case reflect.Ptr, reflect.Interface:
+			if !v.IsNil() {
+				walk(v.Elem(), name)
+				return
+			}
+		}
+		if name == "" {
+			name = "state"
+		}
+		names = append(names, name)
+		values[name] = fmt.Sprint(v)
+	}
+	walk(reflect.ValueOf(s), "")
+	return names, values
+}
+
+func joinFieldName(prefix, field string) string {
+	if prefix == "" {
+		return field
+	}
+	return prefix + "." + field
+}
+
+// mergeFieldNames returns the names of a, followed by the names of b not in a.
+func mergeFieldNames(a, b []string) []string {
+	seen := make(map[string]bool)
+	merged := []string{}
+	for _, names := range [][]string{a, b} {
+		for _, n := range names {
+			if !seen[n] {
+				seen[n] = true
+				merged = append(merged, n)
+			}
+		}
+	}
+	return merged
+}
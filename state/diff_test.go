@@ -0,0 +1,57 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type diffState struct {
+	Name    string
+	Servers [2]bool
+	Tags    []string
+	Load    map[string]int
+	Next    *diffState
+	private int
+}
+
+func TestDiffStates(t *testing.T) {
+	a := diffState{Name: "a", Tags: []string{"x"}, Load: map[string]int{"s1": 1}, private: 1}
+	b := diffState{Name: "a", Servers: [2]bool{false, true}, Tags: []string{"x", "y"}, Load: map[string]int{"s1": 2}, Next: &diffState{Name: "n"}, private: 2}
+	changes := DiffStates(a, b)
+	assert.Contains(t, changes, FieldChange{Field: "Servers[1]", Before: "false", After: "true"})
+	assert.Contains(t, changes, FieldChange{Field: "Tags[1]", Before: "-", After: "y"})
+	assert.Contains(t, changes, FieldChange{Field: "Load[s1]", Before: "1", After: "2"})
+	assert.Contains(t, changes, FieldChange{Field: "private", Before: "1", After: "2"})
+	assert.Contains(t, changes, FieldChange{Field: "Next", Before: "<nil>", After: "-"})
+	assert.Contains(t, changes, FieldChange{Field: "Next.Name", Before: "-", After: "n"})
+	assert.Empty(t, DiffStates(a, a))
+	assert.Equal(t, []FieldChange{{Field: "state", Before: "1", After: "2"}}, DiffStates(1, 2))
+}
+
+func getDiffTrace() Trace {
+	return Trace{
+		States:      []interface{}{counters{}, counters{A: 1}, counters{A: 1, B: 1}, counters{A: 1, B: 1}},
+		Transitions: []string{"IncA", "IncB", "Noop"},
+	}
+}
+
+func TestTraceFormatPlain(t *testing.T) {
+	assert.Equal(t, "0\t{0 0}\n"+
+		"0->1\tIncA\n\tA: 0 -> 1\n"+
+		"1->2\tIncB\n\tB: 0 -> 1\n"+
+		"2->3\tNoop\n\t(no change)\n", getDiffTrace().Format(FormatPlain))
+}
+
+func TestTraceFormatColor(t *testing.T) {
+	assert.Contains(t, getDiffTrace().Format(FormatColor), "\tA: \x1b[31m0\x1b[0m -> \x1b[32m1\x1b[0m\n")
+}
+
+func TestTraceFormatMarkdown(t *testing.T) {
+	assert.Equal(t, "| # | transition | A | B |\n"+
+		"|---|---|---|---|\n"+
+		"| 0 |  | 0 | 0 |\n"+
+		"| 1 | IncA | 1 |  |\n"+
+		"| 2 | IncB |  | 1 |\n"+
+		"| 3 | Noop |  |  |\n", getDiffTrace().Format(FormatMarkdown))
+}
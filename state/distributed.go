@@ -0,0 +1,433 @@
+package state
+
+// Distributed checking. The state graph is explored level by level (BFS) by a Coordinator, but the states themselves
+// are kept by the worker processes. Each worker owns a slice of the hash space (hash modulo the number of workers),
+// i.e. it stores the states falling into its slice, remembers which of them were already seen, and expands them with
+// the transitions. The coordinator routes the new states to their owners in batches of at most BatchSize states per
+// worker, so it holds only a few batches at a time, not a whole level of the graph (unless the graph is collected at
+// the end). The workers keep the edges only when the graph is collected.
+//
+// The workers must run the same model as the coordinator, i.e. typically the same binary started with different
+// arguments. The states are sent over the wire with encoding/gob, so, as with hashing, the private fields are lost.
+// The type of the InitialState is registered with gob automatically, other types must be registered with gob.Register.
+// The counterexamples are the shortest traces, the costs of the transitions are not taken into account.
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/jakub-m/formaggo/log"
+)
+
+// Coordinator runs the Checker on the worker processes listening at the Workers TCP addresses.
+type Coordinator struct {
+	Checker Checker
+	// Workers are TCP addresses of the workers, e.g. "localhost:7001". There must be at least one worker.
+	Workers []string
+	// CollectGraph fetches the whole graph from the workers after the exploration. The graph is always collected
+	// when the Checker has temporal properties. Otherwise the returned graph is empty.
+	CollectGraph bool
+	// BatchSize is the maximum number of the next states a worker returns at once. Defaults to 1000.
+	BatchSize int
+}
+
+const defaultBatchSize = 1000
+
+// Run explores the state graph on the workers. The error is returned when the workers cannot be reached or fail.
+func (c Coordinator) Run() (StateGraph, *Violation, error) {
+	sg := newStateGraph(c.Checker)
+	if len(c.Workers) == 0 {
+		return sg, nil, errors.New("no workers")
+	}
+	gob.Register(c.Checker.InitialState)
+
+	log.Printf("Start coordinator with %d workers\n", len(c.Workers))
+	workers := []*workerConn{}
+	defer func() {
+		for _, w := range workers {
+			w.conn.Close()
+		}
+	}()
+	for _, addr := range c.Workers {
+		w, err := dialWorker(addr)
+		if err != nil {
+			return sg, nil, err
+		}
+		workers = append(workers, w)
+	}
+	owner := func(h stateHash) *workerConn {
+		return workers[uint64(h)%uint64(len(workers))]
+	}
+
+	batchSize := c.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	collect := c.CollectGraph || len(c.Checker.NamedProperties) > 0
+
+	initialState := c.Checker.InitialState
+	initialStateHash := GetHash(initialState)
+	_, err := owner(initialStateHash).call(workerRequest{
+		Op:     opInsert,
+		States: []wireState{{Hash: initialStateHash, State: initialState}},
+	})
+	if err != nil {
+		return sg, nil, err
+	}
+	if _, err := callAll(workers, func(int) workerRequest { return workerRequest{Op: opNextLevel} }); err != nil {
+		return sg, nil, err
+	}
+
+	progress := newProgressReporter(c.Checker)
+	progress.stats.DistinctStates = 1
+	progress.stats.Coverage = Coverage{} // not gathered from the workers
+	for level := 0; ; level++ {
+		// Expand the level in batches, until none of the workers has more states of the level.
+		for more := true; more; {
+			expanded, err := callAll(workers, func(int) workerRequest {
+				return workerRequest{Op: opExpand, Limit: batchSize, Collect: collect}
+			})
+			if err != nil {
+				return sg, nil, err
+			}
+
+			for _, resp := range expanded {
+				if resp.Violation != nil {
+					violation, err := c.buildViolation(*resp.Violation, owner)
+					return sg, violation, err
+				}
+			}
+
+			more = false
+			batches := make([][]wireState, len(workers))
+			for _, resp := range expanded {
+				more = more || resp.More
+				progress.stats.StatesFound += len(resp.States)
+				for _, ws := range resp.States {
+					i := uint64(ws.Hash) % uint64(len(workers))
+					batches[i] = append(batches[i], ws)
+				}
+			}
+			if _, err := callAll(workers, func(i int) workerRequest {
+				return workerRequest{Op: opInsert, States: batches[i]}
+			}); err != nil {
+				return sg, nil, err
+			}
+		}
+
+		nextLevel, err := callAll(workers, func(int) workerRequest { return workerRequest{Op: opNextLevel} })
+		if err != nil {
+			return sg, nil, err
+		}
+		numNew := 0
+		for _, resp := range nextLevel {
+			numNew += resp.NumNew
+		}
+		log.Debugf("level %d, new states %d", level, numNew)
+		progress.stats.DistinctStates += numNew
+		progress.stats.QueueLength = numNew
+		progress.stats.Depth = level + 1
+		progress.maybeReport()
+		if numNew == 0 {
+			break
+		}
+	}
+
+	if !collect {
+		return sg, nil, nil
+	}
+
+	collected, err := callAll(workers, func(int) workerRequest {
+		return workerRequest{Op: opCollect}
+	})
+	if err != nil {
+		return sg, nil, err
+	}
+	for _, resp := range collected {
+		for _, ws := range resp.States {
+			sg.hashToState[ws.Hash] = ws.State
+		}
+		for h, nextHashes := range resp.Graph {
+			sg.hashGraph[h] = nextHashes
+		}
+		for _, we := range resp.Edges {
+			sg.edgeTransitions[hashEdge{we.From, we.To}] = we.Transitions
+		}
+	}
+	log.Printf("Done collecting graph of size: %d\n", sg.NumStates())
+	return sg, c.Checker.runTemporalChecks(sg), nil
+}
+
+// buildViolation follows the parents of the violating state back to the initial state, asking the owner of each state.
+func (c Coordinator) buildViolation(wv wireViolation, owner func(stateHash) *workerConn) (*Violation, error) {
+	path := []interface{}{}
+	h := wv.CurrHash
+	for {
+		resp, err := owner(h).call(workerRequest{Op: opGetParent, Hash: h})
+		if err != nil {
+			return nil, err
+		}
+		ws := resp.States[0]
+		path = append(path, ws.State)
+		if !ws.HasParent {
+			break
+		}
+		h = ws.Parent
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return &Violation{
+		Inv:              &c.Checker.NamedInvariants[wv.InvIndex],
+		Curr:             wv.Curr,
+		Next:             wv.Next,
+		Path:             path,
+		namedTransitions: c.Checker.NamedTransitions,
+	}, nil
+}
+
+// ListenAndServeWorker listens on the TCP address and serves the coordinators.
+func ListenAndServeWorker(c Checker, addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	return ServeWorker(c, l)
+}
+
+// ServeWorker accepts the coordinator connections on the listener. Each connection gets its own, empty, slice of the
+// state graph. ServeWorker returns when the listener fails, e.g. when it is closed.
+func ServeWorker(c Checker, l net.Listener) error {
+	gob.Register(c.InitialState)
+	log.Printf("Worker listening on %s\n", l.Addr())
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveWorkerConn(c, conn)
+	}
+}
+
+func serveWorkerConn(c Checker, conn net.Conn) {
+	defer conn.Close()
+	w := newWorker(c)
+	enc := gob.NewEncoder(conn)
+	dec := gob.NewDecoder(conn)
+	for {
+		var req workerRequest
+		if err := dec.Decode(&req); err != nil {
+			if err != io.EOF {
+				log.Printf("Worker failed to read request: %s\n", err)
+			}
+			return
+		}
+		if err := enc.Encode(w.handle(req)); err != nil {
+			log.Printf("Worker failed to write response: %s\n", err)
+			return
+		}
+	}
+}
+
+type workerOp int
+
+const (
+	// opInsert adds the unseen states to the worker and to the frontier of the next level.
+	opInsert workerOp = iota
+	// opExpand runs the transitions on the states in the frontier until it finds Limit next states, and returns them.
+	opExpand
+	// opNextLevel moves to the next level, i.e. the inserted states become the frontier.
+	opNextLevel
+	// opGetParent returns the state with the parent on the shortest path from the initial state.
+	opGetParent
+	// opCollect returns all the states and transitions of the worker.
+	opCollect
+)
+
+type workerRequest struct {
+	Op     workerOp
+	States []wireState
+	Hash   stateHash
+	Limit  int
+	// Collect keeps the edges for opCollect.
+	Collect bool
+}
+
+type workerResponse struct {
+	Err    string
+	NumNew int
+	// More is true if opExpand did not expand all the frontier.
+	More      bool
+	States    []wireState
+	Graph     map[stateHash][]stateHash
+	Edges     []wireEdge
+	Violation *wireViolation
+}
+
+type wireState struct {
+	Hash      stateHash
+	State     interface{}
+	Parent    stateHash
+	HasParent bool
+}
+
+type wireEdge struct {
+	From, To    stateHash
+	Transitions []int
+}
+
+type wireViolation struct {
+	InvIndex   int
+	CurrHash   stateHash
+	Curr, Next interface{}
+}
+
+type worker struct {
+	checker Checker
+	states  map[stateHash]interface{}
+	// parents on the shortest path from the initial state. The initial state has no parent.
+	parents map[stateHash]stateHash
+	graph   map[stateHash][]stateHash
+	edges   []wireEdge
+	// frontier are the states of the current level to expand, nextFrontier are the inserted states of the next level.
+	frontier, nextFrontier []stateHash
+}
+
+func newWorker(c Checker) *worker {
+	return &worker{
+		checker: c,
+		states:  make(map[stateHash]interface{}),
+		parents: make(map[stateHash]stateHash),
+		graph:   make(map[stateHash][]stateHash),
+	}
+}
+
+func (w *worker) handle(req workerRequest) (resp workerResponse) {
+	defer func() {
+		// Do not bring down the worker on a panic in the model, report it to the coordinator instead.
+		if r := recover(); r != nil {
+			resp = workerResponse{Err: fmt.Sprint(r)}
+		}
+	}()
+	switch req.Op {
+	case opInsert:
+		for _, ws := range req.States {
+			if _, ok := w.states[ws.Hash]; ok {
+				continue
+			}
+			w.states[ws.Hash] = ws.State
+			if ws.HasParent {
+				w.parents[ws.Hash] = ws.Parent
+			}
+			w.nextFrontier = append(w.nextFrontier, ws.Hash)
+			resp.NumNew++
+		}
+	case opNextLevel:
+		w.frontier, w.nextFrontier = w.nextFrontier, nil
+		resp.NumNew = len(w.frontier)
+	case opExpand:
+		for len(w.frontier) > 0 && len(resp.States) < req.Limit {
+			currHash := w.frontier[0]
+			w.frontier = w.frontier[1:]
+			curr := w.states[currHash]
+			nextStateHashes := []stateHash{}
+			for nextHash, ns := range w.checker.nextStates(curr) {
+				next := ns.state
+				nextStateHashes = append(nextStateHashes, nextHash)
+				if req.Collect {
+					w.edges = append(w.edges, wireEdge{From: currHash, To: nextHash, Transitions: ns.transitions})
+				}
+				if invIndex := w.checker.findViolatedInvariant(curr, next); invIndex != -1 {
+					resp.Violation = &wireViolation{
+						InvIndex: invIndex,
+						CurrHash: currHash,
+						Curr:     curr,
+						Next:     next,
+					}
+					return resp
+				}
+				resp.States = append(resp.States, wireState{Hash: nextHash, State: next, Parent: currHash, HasParent: true})
+			}
+			if req.Collect {
+				w.graph[currHash] = nextStateHashes
+			}
+		}
+		resp.More = len(w.frontier) > 0
+	case opGetParent:
+		s, ok := w.states[req.Hash]
+		if !ok {
+			return workerResponse{Err: fmt.Sprintf("unknown state %v", req.Hash)}
+		}
+		parent, hasParent := w.parents[req.Hash]
+		resp.States = []wireState{{Hash: req.Hash, State: s, Parent: parent, HasParent: hasParent}}
+	case opCollect:
+		for h, s := range w.states {
+			resp.States = append(resp.States, wireState{Hash: h, State: s})
+		}
+		resp.Graph = w.graph
+		resp.Edges = w.edges
+	default:
+		return workerResponse{Err: fmt.Sprintf("unknown op %v", req.Op)}
+	}
+	return resp
+}
+
+type workerConn struct {
+	addr string
+	conn net.Conn
+	enc  *gob.Encoder
+	dec  *gob.Decoder
+}
+
+func dialWorker(addr string) (*workerConn, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &workerConn{
+		addr: addr,
+		conn: conn,
+		enc:  gob.NewEncoder(conn),
+		dec:  gob.NewDecoder(conn),
+	}, nil
+}
+
+func (w *workerConn) call(req workerRequest) (workerResponse, error) {
+	var resp workerResponse
+	if err := w.enc.Encode(req); err != nil {
+		return resp, fmt.Errorf("worker %s: %w", w.addr, err)
+	}
+	if err := w.dec.Decode(&resp); err != nil {
+		return resp, fmt.Errorf("worker %s: %w", w.addr, err)
+	}
+	if resp.Err != "" {
+		return resp, fmt.Errorf("worker %s: %s", w.addr, resp.Err)
+	}
+	return resp, nil
+}
+
+// callAll calls all the workers concurrently and returns the responses in the order of the workers.
+func callAll(workers []*workerConn, getRequest func(int) workerRequest) ([]workerResponse, error) {
+	responses := make([]workerResponse, len(workers))
+	errs := make([]error, len(workers))
+	var wg sync.WaitGroup
+	for i, w := range workers {
+		wg.Add(1)
+		go func(i int, w *workerConn) {
+			defer wg.Done()
+			responses[i], errs[i] = w.call(getRequest(i))
+		}(i, w)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return responses, err
+		}
+	}
+	return responses, nil
+}
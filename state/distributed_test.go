@@ -0,0 +1,134 @@
+package state
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type counters struct {
+	A, B int
+}
+
+func getCountersChecker(limit int) Checker {
+	return Checker{
+		InitialState: counters{},
+		NamedTransitions: []NamedTransition{
+			{
+				Name: "IncA",
+				Transition: Managed(func(sm *StateManager) {
+					next := sm.Curr().(counters)
+					next.A = (next.A + 1) % limit
+					sm.AddNextState(next)
+				}),
+			},
+			{
+				Name: "IncB",
+				Transition: Managed(func(sm *StateManager) {
+					next := sm.Curr().(counters)
+					next.B = (next.B + 1) % limit
+					sm.AddNextState(next)
+				}),
+			},
+		},
+	}
+}
+
+func startWorkers(t *testing.T, c Checker, n int) []string {
+	addrs := []string{}
+	for i := 0; i < n; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		t.Cleanup(func() { l.Close() })
+		go ServeWorker(c, l)
+		addrs = append(addrs, l.Addr().String())
+	}
+	return addrs
+}
+
+func TestDistributedSameGraphAsLocal(t *testing.T) {
+	checker := getCountersChecker(5)
+	local, _, violation := checker.Run()
+	assert.Nil(t, violation)
+
+	// The batch of a single state makes the workers expand each level in many steps.
+	for _, batchSize := range []int{0, 1} {
+		coord := Coordinator{
+			Checker:      checker,
+			Workers:      startWorkers(t, checker, 3),
+			CollectGraph: true,
+			BatchSize:    batchSize,
+		}
+		distributed, violation, err := coord.Run()
+		assert.NoError(t, err)
+		assert.Nil(t, violation)
+		assert.Equal(t, 25, distributed.NumStates())
+		assert.Equal(t, local.hashToState, distributed.hashToState)
+		assert.Equal(t, len(local.hashGraph), len(distributed.hashGraph))
+		for h, nextHashes := range local.hashGraph {
+			assert.ElementsMatch(t, nextHashes, distributed.hashGraph[h])
+		}
+		assert.ElementsMatch(t, local.Edges(), distributed.Edges())
+	}
+}
+
+func TestDistributedViolationTrace(t *testing.T) {
+	checker := getCountersChecker(5)
+	checker.NamedInvariants = []NamedInvariant{
+		{
+			Name: "SumBelow5",
+			Inv: func(curr, next interface{}) bool {
+				c := curr.(counters)
+				return c.A+c.B < 5
+			},
+		},
+	}
+	_, _, localViolation := checker.Run()
+	assert.NotNil(t, localViolation)
+
+	coord := Coordinator{
+		Checker: checker,
+		Workers: startWorkers(t, checker, 3),
+	}
+	graph, violation, err := coord.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, graph.NumStates())
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, "SumBelow5", violation.Inv.Name)
+		assert.Len(t, violation.Path, 6)
+		assert.Equal(t, counters{}, violation.Path[0])
+		assert.Equal(t, violation.Curr, violation.Path[len(violation.Path)-1])
+		assert.NotContains(t, violation.String(), "Illegal transition")
+	}
+}
+
+func TestDistributedNoWorkers(t *testing.T) {
+	_, _, err := Coordinator{Checker: getCountersChecker(2)}.Run()
+	assert.Error(t, err)
+}
+
+func TestWorkerExpandsInBatches(t *testing.T) {
+	w := newWorker(getCountersChecker(5))
+	w.handle(workerRequest{Op: opInsert, States: []wireState{{Hash: GetHash(counters{}), State: counters{}}}})
+	w.handle(workerRequest{Op: opNextLevel})
+	// The next states are the stutter, {1 0} and {0 1}.
+	resp := w.handle(workerRequest{Op: opExpand, Limit: 1})
+	assert.Len(t, resp.States, 3)
+	assert.False(t, resp.More)
+	assert.Empty(t, w.edges, "edges are kept only when collecting the graph")
+	assert.Empty(t, w.graph)
+
+	w.handle(workerRequest{Op: opInsert, States: resp.States})
+	assert.Equal(t, 2, w.handle(workerRequest{Op: opNextLevel}).NumNew)
+	resp = w.handle(workerRequest{Op: opExpand, Limit: 1, Collect: true})
+	assert.Len(t, resp.States, 3)
+	assert.True(t, resp.More)
+	assert.Len(t, w.edges, 3)
+}
+
+func TestWorkerBadRequest(t *testing.T) {
+	w := newWorker(getCountersChecker(5))
+	assert.Equal(t, "unknown state 1", w.handle(workerRequest{Op: opGetParent, Hash: 1}).Err)
+	assert.Equal(t, "unknown op 42", w.handle(workerRequest{Op: 42}).Err)
+}
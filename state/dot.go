@@ -54,3 +54,43 @@ func (g StateGraph) ExportToDot(w io.Writer) error {
 	}
 	return nil
 }
+
+type jsonGraphState struct {
+	ID    string      `json:"id"`
+	State interface{} `json:"state"`
+}
+
+type jsonGraphEdge struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Transitions []string `json:"transitions"`
+}
+
+type jsonGraph struct {
+	Initial string           `json:"initial"`
+	States  []jsonGraphState `json:"states"`
+	Edges   []jsonGraphEdge  `json:"edges"`
+}
+
+// ExportToJSON writes the graph as a JSON object with the id of the initial state, the states with their ids, and the
+// edges with the names of the transitions. The ids are the same as in ExportToDot.
+func (g StateGraph) ExportToJSON(w io.Writer) error {
+	jg := jsonGraph{
+		Initial: fmt.Sprintf("s%d", g.initialHash),
+		States:  []jsonGraphState{},
+		Edges:   []jsonGraphEdge{},
+	}
+	for _, h := range g.sortedHashes() {
+		jg.States = append(jg.States, jsonGraphState{ID: fmt.Sprintf("s%d", h), State: g.hashToState[h]})
+		for _, next := range g.hashGraph[h] {
+			jg.Edges = append(jg.Edges, jsonGraphEdge{
+				From:        fmt.Sprintf("s%d", h),
+				To:          fmt.Sprintf("s%d", next),
+				Transitions: g.transitionLabels(h, next),
+			})
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(jg)
+}
@@ -0,0 +1,26 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportToJSON(t *testing.T) {
+	g, _, _ := getRingChecker().Run()
+	var buf bytes.Buffer
+	assert.NoError(t, g.ExportToJSON(&buf))
+	var decoded jsonGraph
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, fmt.Sprintf("s%d", GetHash(0)), decoded.Initial)
+	assert.Len(t, decoded.States, 5)
+	assert.Len(t, decoded.Edges, 10)
+	assert.Contains(t, decoded.Edges, jsonGraphEdge{
+		From:        fmt.Sprintf("s%d", GetHash(2)),
+		To:          fmt.Sprintf("s%d", GetHash(3)),
+		Transitions: []string{"Escape"},
+	})
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/jakub-m/formaggo/log"
+	spa "github.com/jakub-m/formaggo/shortestpath"
+)
+
+// Trace is a path of states, together with the names of the transitions between the states.
+type Trace struct {
+	States []interface{} `json:"states"`
+	// Transitions has the names of the transitions, Transitions[i] leads from States[i] to States[i+1].
+	Transitions []string `json:"transitions"`
+	// Cost is the total cost of the transitions, see NamedTransition.Cost.
+	Cost int `json:"cost,omitempty"`
+}
+
+func (t Trace) String() string {
+	s := ""
+	for i, st := range t.States {
+		s += fmt.Sprintf("%d\t%v\n", i, st)
+		if i < len(t.Transitions) {
+			s += fmt.Sprintf("%d->%d\t%s\n", i, i+1, t.Transitions[i])
+		}
+	}
+	if t.Cost != 0 {
+		s += fmt.Sprintf("cost: %d\n", t.Cost)
+	}
+	return s
+}
+
+// FindState searches for a state meeting the goal condition, starting from the initial state. It returns the cheapest
+// trace to the goal (the shortest one if the transitions have no costs), or false if the goal is unreachable. The
+// invariants and properties are not checked.
+func (c Checker) FindState(goal StateCondition) (Trace, bool) {
+	log.Println("Start searching for the goal state")
+	trace, found := c.findCheapestTrace(func(_ stateHash, s interface{}) bool {
+		return goal(s)
+	}, nil)
+	if found {
+		log.Printf("Goal found at depth %d\n", len(trace.States)-1)
+	} else {
+		log.Println("Goal unreachable")
+	}
+	return trace, found
+}
+
+// stepFilter tells if the search can take the step from curr to next.
+type stepFilter func(currHash stateHash, curr interface{}, nextHash stateHash, next interface{}) bool
+
+// findCheapestTrace explores the states starting from the initial state, until it finds the goal. Only the steps
+// passing the filter are taken, if the filter is not nil.
+func (c Checker) findCheapestTrace(isGoal func(stateHash, interface{}) bool, filter stepFilter) (Trace, bool) {
+	initialHash := GetHash(c.InitialState)
+	hashToState := map[stateHash]interface{}{initialHash: c.InitialState}
+
+	getNext := func(v spa.Vertex) []spa.Edge {
+		curr := hashToState[stateHash(v)]
+		edges := []spa.Edge{}
+		for nextHash, ns := range c.nextStates(curr) {
+			if filter != nil && !filter(stateHash(v), curr, nextHash, ns.state) {
+				continue
+			}
+			hashToState[nextHash] = ns.state
+			cost, _ := c.stepCost(curr, ns)
+			edges = append(edges, spa.Edge{To: spa.Vertex(nextHash), Cost: cost})
+		}
+		return edges
+	}
+	isEnd := func(v spa.Vertex) bool {
+		return isGoal(stateHash(v), hashToState[stateHash(v)])
+	}
+
+	path := spa.FindFirst(spa.Vertex(initialHash), isEnd, getNext)
+	if len(path) == 0 {
+		return Trace{}, false
+	}
+
+	trace := Trace{States: []interface{}{c.InitialState}}
+	for i := 1; i < len(path); i++ {
+		curr := hashToState[stateHash(path[i-1])]
+		ns, ok := c.nextStates(curr)[stateHash(path[i])]
+		if !ok {
+			panic(fmt.Sprintf("RATS! no transition from %v to %v", curr, hashToState[stateHash(path[i])]))
+		}
+		cost, transitionIndex := c.stepCost(curr, ns)
+		trace.States = append(trace.States, ns.state)
+		trace.Transitions = append(trace.Transitions, c.NamedTransitions[transitionIndex].Name)
+		trace.Cost += cost
+	}
+	return trace, true
+}
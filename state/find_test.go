@@ -0,0 +1,109 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindState(t *testing.T) {
+	checker := getCountersChecker(5)
+	trace, found := checker.FindState(func(s interface{}) bool {
+		return s == counters{A: 2, B: 1}
+	})
+	assert.True(t, found)
+	assert.Len(t, trace.States, 4)
+	assert.Len(t, trace.Transitions, 3)
+	assert.Equal(t, counters{}, trace.States[0])
+	assert.Equal(t, counters{A: 2, B: 1}, trace.States[3])
+	assert.ElementsMatch(t, []string{"IncA", "IncA", "IncB"}, trace.Transitions)
+}
+
+func TestFindStateInitial(t *testing.T) {
+	checker := getCountersChecker(5)
+	trace, found := checker.FindState(StateEquals(counters{}))
+	assert.True(t, found)
+	assert.Equal(t, Trace{States: []interface{}{counters{}}}, trace)
+}
+
+func TestFindStateUnreachable(t *testing.T) {
+	checker := getCountersChecker(5)
+	_, found := checker.FindState(StateEquals(counters{A: 5}))
+	assert.False(t, found)
+}
+
+func getJumpChecker(withCosts bool) Checker {
+	step := func(n, cost int) NamedTransition {
+		t := NamedTransition{
+			Name: fmt.Sprintf("Add%d", n),
+			Transition: func(curr interface{}) []interface{} {
+				return []interface{}{(curr.(int) + n) % 10}
+			},
+		}
+		if withCosts {
+			t.Cost = func(curr, next interface{}) int { return cost }
+		}
+		return t
+	}
+	return Checker{
+		InitialState:     0,
+		NamedTransitions: []NamedTransition{step(3, 10), step(1, 1)},
+	}
+}
+
+func TestFindStateCheapest(t *testing.T) {
+	trace, found := getJumpChecker(false).FindState(StateEquals(3))
+	assert.True(t, found)
+	assert.Equal(t, Trace{States: []interface{}{0, 3}, Transitions: []string{"Add3"}, Cost: 1}, trace)
+
+	trace, found = getJumpChecker(true).FindState(StateEquals(3))
+	assert.True(t, found)
+	assert.Equal(t, Trace{States: []interface{}{0, 1, 2, 3}, Transitions: []string{"Add1", "Add1", "Add1"}, Cost: 3}, trace)
+}
+
+func TestCheapestCounterExample(t *testing.T) {
+	checker := getJumpChecker(true)
+	checker.NamedInvariants = []NamedInvariant{
+		{
+			Name: "Not3",
+			Inv:  func(curr, next interface{}) bool { return curr != 3 },
+		},
+	}
+	_, _, violation := checker.Run()
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, []interface{}{0, 1, 2, 3}, violation.Path)
+	}
+}
+
+func TestCounterExampleHoldsOtherInvariants(t *testing.T) {
+	// The path to 4 through 2 violates NotFrom2To4, so the counterexample of NotFrom4 must go through 1 and 3.
+	checker := getEdgesChecker(map[int][]int{0: {1, 2}, 1: {3}, 2: {4}, 3: {4}, 4: {5}})
+	checker.NamedInvariants = []NamedInvariant{
+		{
+			Name: "NotFrom2To4",
+			Inv:  func(curr, next interface{}) bool { return !(curr == 2 && next == 4) },
+		},
+		{
+			Name: "NotFrom4",
+			Inv:  func(curr, next interface{}) bool { return curr != 4 },
+		},
+	}
+	// Repeated, since the violation found first depends on the order of the maps.
+	for i := 0; i < 20; i++ {
+		_, _, violation := checker.Run()
+		if !assert.NotNil(t, violation) {
+			return
+		}
+		if violation.Inv.Name == "NotFrom4" {
+			assert.Equal(t, []interface{}{0, 1, 3, 4}, violation.Path)
+		} else {
+			assert.Equal(t, []interface{}{0, 2}, violation.Path)
+		}
+		replayed, err := checker.Replay(violation.Trace())
+		assert.NoError(t, err)
+		if assert.NotNil(t, replayed) {
+			assert.Equal(t, violation.Inv.Name, replayed.Inv.Name)
+		}
+	}
+}
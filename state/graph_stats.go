@@ -0,0 +1,110 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GraphSummary are the statistics of the shape of the state graph. See StateGraph.Summary.
+type GraphSummary struct {
+	States int
+	// Edges is the number of steps between the states, without the stuttering steps.
+	Edges int
+	// Diameter is the length of the longest of the shortest paths from the initial state, i.e. the depth of the graph.
+	Diameter int
+	// DepthHistogram is the number of states at each BFS level from the initial state.
+	DepthHistogram []int
+	// AvgOutDegree and MaxOutDegree are about the number of the next states, without the stuttering steps.
+	AvgOutDegree float64
+	MaxOutDegree int
+	// SCCs is the number of the strongly connected components, LargestSCC is the number of states in the largest one.
+	SCCs       int
+	LargestSCC int
+}
+
+func (s GraphSummary) String() string {
+	levels := []string{}
+	for _, n := range s.DepthHistogram {
+		levels = append(levels, fmt.Sprint(n))
+	}
+	return fmt.Sprintf("states: %d, edges: %d, diameter: %d\n", s.States, s.Edges, s.Diameter) +
+		fmt.Sprintf("states per depth: %s\n", strings.Join(levels, " ")) +
+		fmt.Sprintf("out-degree: avg %.2f, max %d\n", s.AvgOutDegree, s.MaxOutDegree) +
+		fmt.Sprintf("SCCs: %d, largest SCC: %d\n", s.SCCs, s.LargestSCC)
+}
+
+// Summary computes the statistics of the graph.
+func (g StateGraph) Summary() GraphSummary {
+	s := GraphSummary{
+		States:         g.NumStates(),
+		DepthHistogram: g.DepthHistogram(),
+	}
+	s.Diameter = len(s.DepthHistogram) - 1
+	s.AvgOutDegree, s.MaxOutDegree = g.OutDegree()
+	for h, nextHashes := range g.hashGraph {
+		for _, next := range nextHashes {
+			if next != h {
+				s.Edges++
+			}
+		}
+	}
+	for _, scc := range g.hashSCCs() {
+		s.SCCs++
+		if len(scc) > s.LargestSCC {
+			s.LargestSCC = len(scc)
+		}
+	}
+	return s
+}
+
+// Diameter returns the length of the longest of the shortest paths from the initial state, like the depth reported
+// by TLC. It is -1 for an empty graph.
+func (g StateGraph) Diameter() int {
+	return len(g.DepthHistogram()) - 1
+}
+
+// DepthHistogram returns the number of states at each BFS level, starting with the initial state at level 0.
+func (g StateGraph) DepthHistogram() []int {
+	histogram := []int{}
+	if _, ok := g.hashToState[g.initialHash]; !ok {
+		return histogram
+	}
+	visited := map[stateHash]bool{g.initialHash: true}
+	level := []stateHash{g.initialHash}
+	for len(level) > 0 {
+		histogram = append(histogram, len(level))
+		nextLevel := []stateHash{}
+		for _, h := range level {
+			for _, next := range g.hashGraph[h] {
+				if !visited[next] {
+					visited[next] = true
+					nextLevel = append(nextLevel, next)
+				}
+			}
+		}
+		level = nextLevel
+	}
+	return histogram
+}
+
+// OutDegree returns the average and the maximum number of the next states of a state, not counting the stuttering
+// steps.
+func (g StateGraph) OutDegree() (float64, int) {
+	if g.NumStates() == 0 {
+		return 0, 0
+	}
+	total, max := 0, 0
+	for h := range g.hashToState {
+		degree := 0
+		for _, next := range g.hashGraph[h] {
+			if next != h {
+				degree++
+			}
+		}
+		total += degree
+		if degree > max {
+			max = degree
+		}
+	}
+	return float64(total) / float64(g.NumStates()), max
+}
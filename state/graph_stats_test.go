@@ -0,0 +1,58 @@
+package state
+
+import (
+	"bytes"
+	stdlog "log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getGraph12StateGraph() StateGraph {
+	g := StateGraph{
+		hashGraph:   getGraph12(),
+		hashToState: make(map[stateHash]interface{}),
+	}
+	for h := stateHash(0); h <= 12; h++ {
+		g.hashToState[h] = h
+	}
+	return g
+}
+
+func TestDepthHistogram(t *testing.T) {
+	g := getGraph12StateGraph()
+	assert.Equal(t, []int{1, 3, 3, 3, 3}, g.DepthHistogram())
+	assert.Equal(t, 4, g.Diameter())
+	g.initialHash = 12
+	assert.Equal(t, 0, g.Diameter())
+	g.initialHash = 100
+	assert.Equal(t, -1, g.Diameter())
+}
+
+func TestSummary(t *testing.T) {
+	g, _, _ := getRingChecker().Run()
+	assert.Equal(t, GraphSummary{
+		States:         5,
+		Edges:          5,
+		Diameter:       4,
+		DepthHistogram: []int{1, 1, 1, 1, 1},
+		AvgOutDegree:   1,
+		MaxOutDegree:   2,
+		SCCs:           3,
+		LargestSCC:     3,
+	}, g.Summary())
+	assert.Contains(t, g.Summary().String(), "states per depth: 1 1 1 1 1\n")
+}
+
+func TestRunLogSummary(t *testing.T) {
+	var logged bytes.Buffer
+	defer stdlog.SetOutput(stdlog.Writer())
+	stdlog.SetOutput(&logged)
+
+	checker := getRingChecker()
+	checker.Run()
+	assert.NotContains(t, logged.String(), "Graph summary")
+	checker.LogSummary = true
+	checker.Run()
+	assert.Contains(t, logged.String(), "Graph summary:\nstates: 5, edges: 5, diameter: 4\n")
+}
@@ -0,0 +1,297 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jakub-m/formaggo/graph"
+)
+
+// Edge is a step between two states of the graph.
+type Edge struct {
+	From, To interface{}
+	// Transitions are the names of the transitions leading from From to To.
+	Transitions []string
+}
+
+// graphIndex holds the structures derived from the graph, built once and shared between the copies of the graph.
+type graphIndex struct {
+	predecessorsOnce sync.Once
+	predecessors     map[stateHash][]stateHash
+}
+
+// hashPredecessors returns the reverse of hashGraph.
+func (g StateGraph) hashPredecessors() map[stateHash][]stateHash {
+	if g.index == nil {
+		return reverseHashGraph(g.hashGraph)
+	}
+	g.index.predecessorsOnce.Do(func() {
+		g.index.predecessors = reverseHashGraph(g.hashGraph)
+	})
+	return g.index.predecessors
+}
+
+func reverseHashGraph(transMap map[stateHash][]stateHash) map[stateHash][]stateHash {
+	reversed := make(map[stateHash][]stateHash)
+	for h, nextHashes := range transMap {
+		for _, next := range nextHashes {
+			reversed[next] = append(reversed[next], h)
+		}
+	}
+	return reversed
+}
+
+// InitialState returns the state the graph was explored from.
+func (g StateGraph) InitialState() interface{} {
+	return g.hashToState[g.initialHash]
+}
+
+// Contains tells if the state is in the graph.
+func (g StateGraph) Contains(s interface{}) bool {
+	_, ok := g.hashToState[GetHash(s)]
+	return ok
+}
+
+// States returns all the states of the graph, ordered by hash.
+func (g StateGraph) States() []interface{} {
+	return g.hashesToStates(g.sortedHashes())
+}
+
+// Edges returns all the edges of the graph, including the stuttering steps.
+func (g StateGraph) Edges() []Edge {
+	edges := []Edge{}
+	for _, h := range g.sortedHashes() {
+		for _, next := range g.hashGraph[h] {
+			edges = append(edges, g.edge(h, next))
+		}
+	}
+	return edges
+}
+
+// Successors returns the states reachable from the state with a single step, including the state itself if the step
+// can stutter.
+func (g StateGraph) Successors(s interface{}) []interface{} {
+	return g.hashesToStates(g.hashGraph[GetHash(s)])
+}
+
+// Predecessors returns the states from which the state is reachable with a single step.
+func (g StateGraph) Predecessors(s interface{}) []interface{} {
+	return g.hashesToStates(g.hashPredecessors()[GetHash(s)])
+}
+
+// OutgoingEdges returns the edges from the state, with the names of the transitions.
+func (g StateGraph) OutgoingEdges(s interface{}) []Edge {
+	h := GetHash(s)
+	edges := []Edge{}
+	for _, next := range g.hashGraph[h] {
+		edges = append(edges, g.edge(h, next))
+	}
+	return edges
+}
+
+// IncomingEdges returns the edges to the state, with the names of the transitions.
+func (g StateGraph) IncomingEdges(s interface{}) []Edge {
+	h := GetHash(s)
+	edges := []Edge{}
+	for _, prev := range g.hashPredecessors()[h] {
+		edges = append(edges, g.edge(prev, h))
+	}
+	return edges
+}
+
+// ReachableFrom returns all the states reachable from the state, including the state itself.
+func (g StateGraph) ReachableFrom(s interface{}) []interface{} {
+	h := GetHash(s)
+	if _, ok := g.hashToState[h]; !ok {
+		return []interface{}{}
+	}
+	return g.hashesToStates(g.hashReachableFrom(h))
+}
+
+// CanReach tells if there is a path from a to b.
+func (g StateGraph) CanReach(a, b interface{}) bool {
+	_, found := g.ShortestPath(a, b)
+	return found
+}
+
+// ShortestPath returns the shortest trace from a to b, or false if there is none.
+func (g StateGraph) ShortestPath(a, b interface{}) (Trace, bool) {
+	aHash, bHash := GetHash(a), GetHash(b)
+	if _, ok := g.hashToState[aHash]; !ok {
+		return Trace{}, false
+	}
+	p := graph.ShortestPath(aHash, bHash, func(v graph.Vertex) []graph.Vertex {
+		next := []graph.Vertex{}
+		for _, h := range g.hashGraph[v.(stateHash)] {
+			next = append(next, h)
+		}
+		return next
+	})
+	if !p.Found {
+		return Trace{}, false
+	}
+	hashPath := []stateHash{}
+	for _, v := range p.Vertices {
+		hashPath = append(hashPath, v.(stateHash))
+	}
+	return g.hashPathToTrace(hashPath), true
+}
+
+// TerminalStates returns the states that have no steps to other states (deadlocks), ordered by hash.
+func (g StateGraph) TerminalStates() []interface{} {
+	terminal := []stateHash{}
+	for _, h := range g.sortedHashes() {
+		if g.isTerminal(h) {
+			terminal = append(terminal, h)
+		}
+	}
+	return g.hashesToStates(terminal)
+}
+
+// StronglyConnectedComponents returns the groups of states where each state is reachable from every other state of
+// the group. Every state is in exactly one component.
+func (g StateGraph) StronglyConnectedComponents() [][]interface{} {
+	components := [][]interface{}{}
+	for _, scc := range g.hashSCCs() {
+		components = append(components, g.hashesToStates(scc))
+	}
+	return components
+}
+
+func (g StateGraph) isTerminal(h stateHash) bool {
+	for _, next := range g.hashGraph[h] {
+		if next != h {
+			return false
+		}
+	}
+	return true
+}
+
+func (g StateGraph) hashReachableFrom(start stateHash) []stateHash {
+	visited := map[stateHash]bool{start: true}
+	reachable := []stateHash{start}
+	for i := 0; i < len(reachable); i++ {
+		for _, next := range g.hashGraph[reachable[i]] {
+			if !visited[next] {
+				visited[next] = true
+				reachable = append(reachable, next)
+			}
+		}
+	}
+	return reachable
+}
+
+// hashSCCs returns the strongly connected components with Tarjan's algorithm. The recursion is replaced with an
+// explicit stack, since the graphs can be deep.
+func (g StateGraph) hashSCCs() [][]stateHash {
+	type frame struct {
+		h    stateHash
+		next int
+	}
+	index := make(map[stateHash]int)
+	lowlink := make(map[stateHash]int)
+	onStack := make(map[stateHash]bool)
+	stack := []stateHash{}
+	sccs := [][]stateHash{}
+	counter := 0
+	visit := func(h stateHash) {
+		index[h] = counter
+		lowlink[h] = counter
+		counter++
+		stack = append(stack, h)
+		onStack[h] = true
+	}
+
+	for _, root := range g.sortedHashes() {
+		if _, ok := index[root]; ok {
+			continue
+		}
+		visit(root)
+		callStack := []frame{{h: root}}
+		for len(callStack) > 0 {
+			top := &callStack[len(callStack)-1]
+			nextHashes := g.hashGraph[top.h]
+			if top.next < len(nextHashes) {
+				next := nextHashes[top.next]
+				top.next++
+				if _, ok := index[next]; !ok {
+					visit(next)
+					callStack = append(callStack, frame{h: next})
+				} else if onStack[next] && index[next] < lowlink[top.h] {
+					lowlink[top.h] = index[next]
+				}
+				continue
+			}
+
+			h := top.h
+			callStack = callStack[:len(callStack)-1]
+			if len(callStack) > 0 {
+				parent := callStack[len(callStack)-1].h
+				if lowlink[h] < lowlink[parent] {
+					lowlink[parent] = lowlink[h]
+				}
+			}
+			if lowlink[h] == index[h] {
+				scc := []stateHash{}
+				for {
+					member := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[member] = false
+					scc = append(scc, member)
+					if member == h {
+						break
+					}
+				}
+				sccs = append(sccs, scc)
+			}
+		}
+	}
+	return sccs
+}
+
+func (g StateGraph) edge(from, to stateHash) Edge {
+	return Edge{
+		From:        g.hashToState[from],
+		To:          g.hashToState[to],
+		Transitions: g.transitionLabels(from, to),
+	}
+}
+
+func (g StateGraph) transitionLabels(from, to stateHash) []string {
+	labels := []string{}
+	for _, i := range g.edgeTransitions[hashEdge{from, to}] {
+		labels = append(labels, g.transitionNames[i])
+	}
+	return labels
+}
+
+// hashPathToTrace converts the path to a trace, labelled with the first of the transitions of each step.
+func (g StateGraph) hashPathToTrace(hashPath []stateHash) Trace {
+	t := Trace{States: g.hashesToStates(hashPath)}
+	for i := 0; i+1 < len(hashPath); i++ {
+		labels := g.transitionLabels(hashPath[i], hashPath[i+1])
+		if len(labels) == 0 {
+			panic(fmt.Sprintf("RATS! no transition from %v to %v", hashPath[i], hashPath[i+1]))
+		}
+		t.Transitions = append(t.Transitions, labels[0])
+	}
+	return t
+}
+
+func (g StateGraph) hashesToStates(hashes []stateHash) []interface{} {
+	states := []interface{}{}
+	for _, h := range hashes {
+		states = append(states, g.hashToState[h])
+	}
+	return states
+}
+
+func (g StateGraph) sortedHashes() []stateHash {
+	hashes := []stateHash{}
+	for h := range g.hashToState {
+		hashes = append(hashes, h)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	return hashes
+}
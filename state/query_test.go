@@ -0,0 +1,95 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getRingChecker returns a model of states 0 -> 1 -> 2 -> 0 (a cycle), 2 -> 3 and 3 -> 4 (a deadlock).
+func getRingChecker() Checker {
+	return Checker{
+		InitialState: 0,
+		NamedTransitions: []NamedTransition{
+			{
+				Name: "Ring",
+				Transition: Managed(func(sm *StateManager) {
+					if curr := sm.Curr().(int); curr < 3 {
+						sm.AddNextState((curr + 1) % 3)
+					}
+				}),
+			},
+			{
+				Name: "Escape",
+				Transition: Managed(func(sm *StateManager) {
+					if curr := sm.Curr().(int); curr >= 2 && curr < 4 {
+						sm.AddNextState(curr + 1)
+					}
+				}),
+			},
+		},
+	}
+}
+
+func TestGraphQueries(t *testing.T) {
+	g, _, violation := getRingChecker().Run()
+	assert.Nil(t, violation)
+
+	assert.Equal(t, 0, g.InitialState())
+	assert.ElementsMatch(t, []interface{}{0, 1, 2, 3, 4}, g.States())
+	assert.True(t, g.Contains(4))
+	assert.False(t, g.Contains(5))
+	assert.Len(t, g.Edges(), 10)
+	assert.ElementsMatch(t, []interface{}{2, 0, 3}, g.Successors(2))
+	assert.ElementsMatch(t, []interface{}{1, 2}, g.Predecessors(2))
+	assert.ElementsMatch(t, []Edge{
+		{From: 2, To: 2, Transitions: []string{"Ring", "Escape"}},
+		{From: 2, To: 0, Transitions: []string{"Ring"}},
+		{From: 2, To: 3, Transitions: []string{"Escape"}},
+	}, g.OutgoingEdges(2))
+	assert.ElementsMatch(t, []Edge{
+		{From: 2, To: 3, Transitions: []string{"Escape"}},
+		{From: 3, To: 3, Transitions: []string{"Ring", "Escape"}},
+	}, g.IncomingEdges(3))
+	assert.ElementsMatch(t, []interface{}{3, 4}, g.ReachableFrom(3))
+	assert.Empty(t, g.ReachableFrom(10))
+	assert.True(t, g.CanReach(1, 4))
+	assert.False(t, g.CanReach(4, 1))
+	assert.Equal(t, []interface{}{4}, g.TerminalStates())
+
+	trace, found := g.ShortestPath(1, 4)
+	assert.True(t, found)
+	assert.Equal(t, Trace{
+		States:      []interface{}{1, 2, 3, 4},
+		Transitions: []string{"Ring", "Escape", "Escape"},
+	}, trace)
+	_, found = g.ShortestPath(4, 0)
+	assert.False(t, found)
+
+	sccs := g.StronglyConnectedComponents()
+	assert.Len(t, sccs, 3)
+	for _, scc := range sccs {
+		switch len(scc) {
+		case 3:
+			assert.ElementsMatch(t, []interface{}{0, 1, 2}, scc)
+		case 1:
+			assert.Contains(t, []interface{}{3, 4}, scc[0])
+		default:
+			assert.Fail(t, "unexpected component", scc)
+		}
+	}
+}
+
+func TestHashSCCs(t *testing.T) {
+	g := StateGraph{
+		hashGraph:   getGraph12(),
+		hashToState: make(map[stateHash]interface{}),
+	}
+	for h := stateHash(0); h <= 12; h++ {
+		g.hashToState[h] = h
+	}
+	g.hashGraph[12] = []stateHash{0}
+	sccs := g.hashSCCs()
+	assert.Len(t, sccs, 3) // 10 and 11 are dead ends
+	assert.Len(t, sccs[2], 11)
+}
@@ -0,0 +1,98 @@
+package state
+
+import "fmt"
+
+// RefinementMapping maps a state of the concrete (detailed) model to a state of the abstract model.
+type RefinementMapping func(concrete interface{}) interface{}
+
+// RefinementViolation is a concrete behaviour that is not a behaviour of the abstract model.
+type RefinementViolation struct {
+	// Trace is the shortest concrete trace to the offending step. If the initial state does not map to the abstract
+	// initial state, the trace has only the initial state.
+	Trace Trace
+	// Abstract are the concrete states of the trace mapped to the abstract ones.
+	Abstract []interface{}
+	Reason   string
+}
+
+func (v RefinementViolation) String() string {
+	s := fmt.Sprintf("Violation of refinement: %s\n", v.Reason)
+	for i, st := range v.Trace.States {
+		s += fmt.Sprintf("%d\t%v\n\t-> %v\n", i, st, v.Abstract[i])
+		if i < len(v.Trace.Transitions) {
+			s += fmt.Sprintf("%d->%d\t%s\n", i, i+1, v.Trace.Transitions[i])
+		}
+	}
+	return s
+}
+
+// CheckRefinement checks that the concrete graph implements the abstract one: the concrete initial state maps to the
+// abstract initial state, and every concrete step maps to an abstract step, or to a stuttering step (the same abstract
+// state). Only the safety is checked, not the liveness. Returns nil if the concrete graph refines the abstract one.
+func CheckRefinement(concrete, abstract StateGraph, mapping RefinementMapping) *RefinementViolation {
+	abstractInitial := mapping(concrete.InitialState())
+	if GetHash(abstractInitial) != abstract.initialHash {
+		return &RefinementViolation{
+			Trace:    Trace{States: []interface{}{concrete.InitialState()}},
+			Abstract: []interface{}{abstractInitial},
+			Reason:   fmt.Sprintf("initial state maps to %v, not to the abstract initial state %v", abstractInitial, abstract.InitialState()),
+		}
+	}
+
+	abstractHashOf := func(h stateHash) stateHash {
+		return GetHash(mapping(concrete.hashToState[h]))
+	}
+	// BFS, so the counterexample is the shortest.
+	visited := map[stateHash]bool{concrete.initialHash: true}
+	level := []stateHash{concrete.initialHash}
+	for len(level) > 0 {
+		nextLevel := []stateHash{}
+		for _, h := range level {
+			a := abstractHashOf(h)
+			for _, next := range concrete.hashGraph[h] {
+				aNext := abstractHashOf(next)
+				if a != aNext && !abstract.hasEdge(a, aNext) {
+					return concrete.refinementViolation(h, next, mapping)
+				}
+				if !visited[next] {
+					visited[next] = true
+					nextLevel = append(nextLevel, next)
+				}
+			}
+		}
+		level = nextLevel
+	}
+	return nil
+}
+
+// CheckEquivalence checks that the two graphs refine each other, e.g. that a rewritten model behaves as the
+// original one.
+func CheckEquivalence(a, b StateGraph, aToB, bToA RefinementMapping) *RefinementViolation {
+	if v := CheckRefinement(a, b, aToB); v != nil {
+		return v
+	}
+	return CheckRefinement(b, a, bToA)
+}
+
+func (g StateGraph) hasEdge(from, to stateHash) bool {
+	for _, next := range g.hashGraph[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (g StateGraph) refinementViolation(from, to stateHash, mapping RefinementMapping) *RefinementViolation {
+	trace, _ := g.ShortestPath(g.hashToState[g.initialHash], g.hashToState[from])
+	labels := g.transitionLabels(from, to)
+	trace.States = append(trace.States, g.hashToState[to])
+	trace.Transitions = append(trace.Transitions, labels[0])
+	v := RefinementViolation{Trace: trace}
+	for _, s := range trace.States {
+		v.Abstract = append(v.Abstract, mapping(s))
+	}
+	n := len(v.Abstract)
+	v.Reason = fmt.Sprintf("step %s maps to %v -> %v, which is not an abstract step", labels[0], v.Abstract[n-2], v.Abstract[n-1])
+	return &v
+}
@@ -0,0 +1,108 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTotalChecker() Checker {
+	return Checker{
+		InitialState: 0,
+		NamedTransitions: []NamedTransition{
+			{
+				Name: "Inc",
+				Transition: Managed(func(sm *StateManager) {
+					sm.AddNextState((sm.Curr().(int) + 1) % 3)
+				}),
+			},
+		},
+	}
+}
+
+func totalOfCounters(s interface{}) interface{} {
+	c := s.(counters)
+	return (c.A + c.B) % 3
+}
+
+func TestCheckRefinement(t *testing.T) {
+	concrete, _, _ := getCountersChecker(3).Run()
+	abstract, _, _ := getTotalChecker().Run()
+	assert.Nil(t, CheckRefinement(concrete, abstract, totalOfCounters))
+}
+
+func TestCheckRefinementViolation(t *testing.T) {
+	checker := getCountersChecker(3)
+	checker.NamedTransitions = append(checker.NamedTransitions, NamedTransition{
+		Name: "AddTwo",
+		Transition: Managed(func(sm *StateManager) {
+			next := sm.Curr().(counters)
+			next.A = (next.A + 2) % 3
+			sm.AddNextState(next)
+		}),
+	})
+	concrete, _, _ := checker.Run()
+	abstract, _, _ := getTotalChecker().Run()
+	v := CheckRefinement(concrete, abstract, totalOfCounters)
+	if assert.NotNil(t, v) {
+		assert.Equal(t, Trace{
+			States:      []interface{}{counters{}, counters{A: 2}},
+			Transitions: []string{"AddTwo"},
+		}, v.Trace)
+		assert.Equal(t, []interface{}{0, 2}, v.Abstract)
+		assert.Equal(t, "Violation of refinement: step AddTwo maps to 0 -> 2, which is not an abstract step\n"+
+			"0\t{0 0}\n\t-> 0\n"+
+			"0->1\tAddTwo\n"+
+			"1\t{2 0}\n\t-> 2\n", v.String())
+	}
+}
+
+func TestCheckRefinementInitial(t *testing.T) {
+	checker := getCountersChecker(3)
+	checker.InitialState = counters{A: 1}
+	concrete, _, _ := checker.Run()
+	abstract, _, _ := getTotalChecker().Run()
+	v := CheckRefinement(concrete, abstract, totalOfCounters)
+	if assert.NotNil(t, v) {
+		assert.Equal(t, []interface{}{counters{A: 1}}, v.Trace.States)
+		assert.Equal(t, "initial state maps to 1, not to the abstract initial state 0", v.Reason)
+	}
+}
+
+func TestCheckEquivalence(t *testing.T) {
+	// The same model, with the counters kept in an array.
+	inc := func(i int) Transition {
+		return Managed(func(sm *StateManager) {
+			next := sm.Curr().([2]int)
+			next[i] = (next[i] + 1) % 3
+			sm.AddNextState(next)
+		})
+	}
+	rewritten := Checker{
+		InitialState: [2]int{},
+		NamedTransitions: []NamedTransition{
+			{Name: "Inc0", Transition: inc(0)},
+			{Name: "Inc1", Transition: inc(1)},
+		},
+	}
+	a, _, _ := getCountersChecker(3).Run()
+	b, _, _ := rewritten.Run()
+	toArray := func(s interface{}) interface{} {
+		c := s.(counters)
+		return [2]int{c.A, c.B}
+	}
+	toCounters := func(s interface{}) interface{} {
+		arr := s.([2]int)
+		return counters{A: arr[0], B: arr[1]}
+	}
+	assert.Nil(t, CheckEquivalence(a, b, toArray, toCounters))
+
+	// A step of the total cannot increment both of the counters at once.
+	total, _, _ := getTotalChecker().Run()
+	v := CheckEquivalence(a, total, totalOfCounters, func(s interface{}) interface{} {
+		return counters{A: s.(int), B: s.(int)}
+	})
+	if assert.NotNil(t, v) {
+		assert.Equal(t, []interface{}{0, 1}, v.Trace.States)
+	}
+}
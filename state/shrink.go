@@ -0,0 +1,89 @@
+package state
+
+// maxShortcutStates limits the number of states explored when searching for a shortcut from a single state of the
+// trace.
+const maxShortcutStates = 10000
+
+// ShrinkViolation tries to shorten the counterexample of an invariant, e.g. one found by a random walk or replayed from
+// a log. For each state of the trace, it searches for a shortcut to a later state of the trace, or for a shorter way
+// to violate the same invariant, and drops the detours, until the trace cannot be shortened anymore. The steps before
+// the last one violate no invariant. The search from each state is bounded, so the result is not necessarily the
+// shortest counterexample. The violations of temporal properties are returned unchanged.
+func (c Checker) ShrinkViolation(v Violation) Violation {
+	if v.Inv == nil || v.Next == nil {
+		return v
+	}
+	states := v.Trace().States
+	for shortened := true; shortened; {
+		shortened = false
+		for i := 0; i < len(states)-2; i++ {
+			if shorter, ok := c.findShortcut(states, i, v.Inv.Inv); ok {
+				states, shortened = shorter, true
+				break
+			}
+		}
+	}
+	n := len(states)
+	return Violation{
+		Inv:              v.Inv,
+		Curr:             states[n-2],
+		Next:             states[n-1],
+		Path:             states[:n-1],
+		namedTransitions: c.NamedTransitions,
+	}
+}
+
+// findShortcut explores the states with BFS from the state at position i of the trace, and returns the shortest of the
+// traces that either continue from a later state of the trace, or end with a step violating the invariant. The steps
+// violating any invariant are not followed. Returns false if there is no trace shorter than the given one.
+func (c Checker) findShortcut(states []interface{}, i int, inv Invariant) ([]interface{}, bool) {
+	position := make(map[stateHash]int)
+	for j := i + 1; j < len(states)-1; j++ {
+		position[GetHash(states[j])] = j
+	}
+	startHash := GetHash(states[i])
+	hashToState := map[stateHash]interface{}{startHash: states[i]}
+	parent := make(map[stateHash]stateHash)
+	pathTo := func(h stateHash) []interface{} {
+		path := []interface{}{hashToState[h]}
+		for h != startHash {
+			h = parent[h]
+			path = append([]interface{}{hashToState[h]}, path...)
+		}
+		return append(append([]interface{}{}, states[:i]...), path...)
+	}
+
+	var best []interface{}
+	bestLen := len(states)
+	level := []stateHash{startHash}
+	// At depth d, a shortcut to position j gives len(states)-(j-i-d) states, and a violating step gives i+d+2 states.
+	for depth := 0; len(level) > 0 && i+depth+2 < bestLen && len(hashToState) < maxShortcutStates; depth++ {
+		for _, h := range level {
+			if j, ok := position[h]; ok && len(states)-(j-i-depth) < bestLen {
+				bestLen = len(states) - (j - i - depth)
+				best = append(pathTo(h), states[j+1:]...)
+			}
+		}
+		nextLevel := []stateHash{}
+		for _, h := range level {
+			curr := hashToState[h]
+			for nextHash, ns := range c.nextStates(curr) {
+				if !inv(curr, ns.state) {
+					if i+depth+2 < bestLen {
+						bestLen = i + depth + 2
+						best = append(pathTo(h), ns.state)
+					}
+					continue
+				}
+				if _, ok := hashToState[nextHash]; ok || c.findViolatedInvariant(curr, ns.state) != -1 {
+					continue
+				}
+				hashToState[nextHash] = ns.state
+				parent[nextHash] = h
+				nextLevel = append(nextLevel, nextHash)
+			}
+		}
+		level = nextLevel
+	}
+	return best, best != nil
+}
@@ -0,0 +1,32 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShrinkViolation(t *testing.T) {
+	c := getCountersCheckerWithInvariant()
+	long, err := c.Replay(Trace{States: []interface{}{
+		counters{}, counters{B: 1}, counters{B: 2}, counters{}, counters{B: 1}, counters{A: 1, B: 1}, counters{A: 2, B: 1},
+	}})
+	assert.NoError(t, err)
+	if !assert.NotNil(t, long) {
+		return
+	}
+	assert.Len(t, long.Trace().States, 7)
+
+	shrunk := c.ShrinkViolation(*long)
+	assert.Equal(t, "ABelow2", shrunk.Inv.Name)
+	assert.Equal(t, Trace{
+		States:      []interface{}{counters{}, counters{A: 1}, counters{A: 2}},
+		Transitions: []string{"IncA", "IncA"},
+	}, shrunk.Trace())
+	assert.False(t, shrunk.Inv.Inv(shrunk.Curr, shrunk.Next))
+}
+
+func TestShrinkViolationProperty(t *testing.T) {
+	v := Violation{Prop: &NamedTemporalProperty{Name: "Prop"}, Path: []interface{}{counters{}}}
+	assert.Equal(t, v, getCountersChecker(3).ShrinkViolation(v))
+}
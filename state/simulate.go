@@ -0,0 +1,75 @@
+package state
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/jakub-m/formaggo/log"
+)
+
+// Simulation are the parameters of Checker.Simulate.
+type Simulation struct {
+	// Runs is the number of the random walks.
+	Runs int
+	// Depth is the number of the steps of each walk.
+	Depth int
+	// Seed makes the walks repeatable.
+	Seed int64
+}
+
+// Simulate checks the invariants on random walks from the initial state, instead of exploring all the states. It is
+// useful for the models too large to check exhaustively. The next state is picked uniformly from the distinct next
+// states. The temporal properties are not checked. Returns the first violation found, or nil. The violation trace can
+// be shortened with Checker.ShrinkViolation.
+func (c Checker) Simulate(sim Simulation) (Stats, *Violation) {
+	log.Printf("Start simulation of %d runs of depth %d, seed %d\n", sim.Runs, sim.Depth, sim.Seed)
+	rnd := rand.New(rand.NewSource(sim.Seed))
+	progress := newProgressReporter(c)
+	seen := make(map[stateHash]bool)
+	finalStats := func() Stats {
+		progress.stats.DistinctStates = len(seen)
+		return progress.snapshot()
+	}
+	for run := 0; run < sim.Runs; run++ {
+		curr := c.InitialState
+		path := []interface{}{curr}
+		seen[GetHash(curr)] = true
+		for step := 0; step < sim.Depth; step++ {
+			nextStates := c.nextStates(curr)
+			progress.stats.StatesFound += len(nextStates)
+			hashes := []stateHash{}
+			for h := range nextStates {
+				hashes = append(hashes, h)
+			}
+			// Sorted, so that the walks depend only on the seed.
+			sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+			currHash := GetHash(curr)
+			nextHash := hashes[rnd.Intn(len(hashes))]
+			ns := nextStates[nextHash]
+			progress.stats.Coverage.countTransitions(ns.transitions, nextHash == currHash, !seen[nextHash])
+			seen[nextHash] = true
+
+			invIndex := c.findViolatedInvariant(curr, ns.state)
+			if invIndex != -1 {
+				progress.stats.Coverage.countInvariants(invIndex+1, nextHash == currHash)
+				log.Printf("Violation found in run %d at depth %d\n", run, step+1)
+				return finalStats(), &Violation{
+					Inv:              &c.NamedInvariants[invIndex],
+					Curr:             curr,
+					Next:             ns.state,
+					Path:             path,
+					namedTransitions: c.NamedTransitions,
+				}
+			}
+			progress.stats.Coverage.countInvariants(len(c.NamedInvariants), nextHash == currHash)
+			curr = ns.state
+			path = append(path, curr)
+			if step+1 > progress.stats.Depth {
+				progress.stats.Depth = step + 1
+			}
+			progress.stats.DistinctStates = len(seen)
+			progress.maybeReport()
+		}
+	}
+	return finalStats(), nil
+}
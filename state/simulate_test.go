@@ -0,0 +1,29 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulate(t *testing.T) {
+	c := getCountersCheckerWithInvariant()
+	stats, violation := c.Simulate(Simulation{Runs: 10, Depth: 20, Seed: 1})
+	if assert.NotNil(t, violation) {
+		assert.Equal(t, "ABelow2", violation.Inv.Name)
+		assert.False(t, violation.Inv.Inv(violation.Curr, violation.Next))
+		_, err := c.Replay(violation.Trace())
+		assert.NoError(t, err)
+	}
+	assert.Greater(t, stats.StatesFound, 0)
+
+	_, again := c.Simulate(Simulation{Runs: 10, Depth: 20, Seed: 1})
+	assert.Equal(t, violation.Trace(), again.Trace())
+}
+
+func TestSimulateNoViolation(t *testing.T) {
+	stats, violation := getCountersChecker(3).Simulate(Simulation{Runs: 5, Depth: 10, Seed: 2})
+	assert.Nil(t, violation)
+	assert.Equal(t, 10, stats.Depth)
+	assert.LessOrEqual(t, stats.DistinctStates, 9)
+}
@@ -2,11 +2,10 @@ package state
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/jakub-m/formaggo/log"
 
-	spa "github.com/jakub-m/formaggo/shortestpath"
-
 	"github.com/mitchellh/hashstructure/v2"
 )
 
@@ -19,11 +18,57 @@ type Checker struct {
 	NamedInvariants []NamedInvariant
 	// NamedProperties must hold for all the possible paths in the state transition graph. Optional
 	NamedProperties []NamedTemporalProperty
+	// Progress is called periodically during Run with the statistics so far. Optional.
+	Progress func(Stats)
+	// ProgressInterval is how often Progress is called. Defaults to one second.
+	ProgressInterval time.Duration
+	// Strategy is the order the states are explored in. Defaults to DFS.
+	Strategy SearchStrategy
+	// MaxDepth stops the exploration at the states that deep, they are not expanded. 0 means no limit. When the limit is
+	// reached, the graph is partial and the temporal properties are not checked.
+	MaxDepth int
+	// LogSummary logs the summary of the graph at the end of Run, see StateGraph.Summary. Off by default, since
+	// finding the SCCs of a large graph takes a while.
+	LogSummary bool
 }
 
+// SearchStrategy is the order the states are explored in by Checker.Run.
+type SearchStrategy int
+
+const (
+	// DFS explores the last found state first. It usually reaches deep violations quickly.
+	DFS SearchStrategy = iota
+	// BFS explores the states level by level, so the depths in the stats are the distances from the initial state.
+	BFS
+)
+
 type StateGraph struct {
 	hashGraph   map[stateHash][]stateHash
 	hashToState map[stateHash]interface{}
+	initialHash stateHash
+	// edgeTransitions are indices of transitionNames leading from one state to another.
+	edgeTransitions map[hashEdge][]int
+	transitionNames []string
+	// index is built lazily, on the first query that needs it. Can be nil.
+	index *graphIndex
+}
+
+type hashEdge struct {
+	from, to stateHash
+}
+
+func newStateGraph(c Checker) StateGraph {
+	sg := StateGraph{
+		hashGraph:       make(map[stateHash][]stateHash),
+		hashToState:     make(map[stateHash]interface{}),
+		initialHash:     GetHash(c.InitialState),
+		edgeTransitions: make(map[hashEdge][]int),
+		index:           &graphIndex{},
+	}
+	for _, t := range c.NamedTransitions {
+		sg.transitionNames = append(sg.transitionNames, t.Name)
+	}
+	return sg
 }
 
 func (g StateGraph) NumStates() int {
@@ -44,40 +89,103 @@ type TemporalProperty struct {
 type NamedTransition struct {
 	Name       string
 	Transition Transition
+	// Cost of the step from curr to next state. The cost must not be negative. Optional, by default each step costs 1.
+	// The goal searches and the counterexamples of invariants minimise the total cost.
+	Cost func(curr, next interface{}) int
 }
 
-func (c Checker) Run() (StateGraph, *Violation) {
+// Run builds the state graph, checks the invariants and then the temporal properties. It returns the statistics of
+// the run, and the first violation found or nil.
+func (c Checker) Run() (StateGraph, Stats, *Violation) {
 	log.Println("Start checker")
-	graph, violation := c.runTransitions()
+	graph, stats, violation := c.runTransitions()
 	if violation != nil {
-		return graph, violation
+		return graph, stats, violation
+	}
+	for _, name := range stats.Coverage.Unused() {
+		stats.Warnings = append(stats.Warnings, fmt.Sprintf("%s never changed or saw a change of the state", name))
+	}
+	stats.Warnings = append(stats.Warnings, c.checkVacuity(graph)...)
+	for _, w := range stats.Warnings {
+		log.Printf("WARNING: %s\n", w)
 	}
 	log.Printf("Done generating graph of size: %d\n", graph.NumStates())
+	if c.LogSummary {
+		log.Printf("Graph summary:\n%s", graph.Summary())
+	}
+	if stats.DepthLimitReached {
+		w := fmt.Sprintf("depth limit %d reached, the temporal properties were not checked", c.MaxDepth)
+		log.Printf("WARNING: %s\n", w)
+		stats.Warnings = append(stats.Warnings, w)
+		return graph, stats, nil
+	}
 	violation = c.runTemporalChecks(graph)
-	return graph, violation
+	return graph, stats, violation
 }
 
-func (c Checker) runTransitions() (StateGraph, *Violation) {
-	sg := StateGraph{
-		hashGraph:   make(map[stateHash][]stateHash),
-		hashToState: make(map[stateHash]interface{}),
-	}
+type backlogItem struct {
+	hash  stateHash
+	depth int
+}
+
+func (c Checker) runTransitions() (StateGraph, Stats, *Violation) {
+	sg := newStateGraph(c)
 
 	initialState := c.InitialState
 	log.Debugln("init", initialState)
-	initialStateHash := GetHash(initialState)
+	initialStateHash := sg.initialHash
 	sg.hashToState[initialStateHash] = initialState
-	backlog := []stateHash{initialStateHash}
+	backlog := []backlogItem{{initialStateHash, 0}}
+	progress := newProgressReporter(c)
+	maxDepth := 0
+	// expandedDepth is the lowest depth each state was expanded at. Used only with MaxDepth.
+	expandedDepth := make(map[stateHash]int)
+	finalStats := func() Stats {
+		progress.stats.DistinctStates = len(sg.hashToState)
+		progress.stats.QueueLength = len(backlog)
+		progress.stats.Depth = maxDepth
+		progress.stats.Diameter = sg.Diameter()
+		progress.stats.DepthLimitReached = c.MaxDepth > 0 && len(backlog) == 0 && len(sg.hashGraph) < len(sg.hashToState)
+		return progress.snapshot()
+	}
 	for len(backlog) > 0 {
 		log.Debugf("backlog %d", len(backlog))
-		currHash := backlog[len(backlog)-1]
-		backlog = backlog[0 : len(backlog)-1]
+		var currItem backlogItem
+		if c.Strategy == BFS {
+			currItem = backlog[0]
+			backlog = backlog[1:]
+		} else {
+			currItem = backlog[len(backlog)-1]
+			backlog = backlog[0 : len(backlog)-1]
+		}
+		currHash := currItem.hash
+		progress.stats.Depth = currItem.depth
+		progress.stats.DistinctStates = len(sg.hashToState)
+		progress.stats.QueueLength = len(backlog)
+		progress.maybeReport()
 
 		if _, ok := sg.hashGraph[currHash]; ok {
+			if c.MaxDepth > 0 && currItem.depth < expandedDepth[currHash] {
+				// Found at a lower depth than it was expanded at, so the states cut off by the depth limit below it
+				// might be within the limit now. The edges are known, only the depths of the next states change.
+				expandedDepth[currHash] = currItem.depth
+				for _, nextHash := range sg.hashGraph[currHash] {
+					backlog = append(backlog, backlogItem{nextHash, currItem.depth + 1})
+				}
+				continue
+			}
 			// The state was already processed, all the transisitons are in the map. No need to do it again.
 			log.Debugf("continue")
 			continue
 		}
+		if c.MaxDepth > 0 && currItem.depth >= c.MaxDepth {
+			// Not expanded, but might be expanded later if found at a lower depth.
+			continue
+		}
+		expandedDepth[currHash] = currItem.depth
+		if currItem.depth > maxDepth {
+			maxDepth = currItem.depth
+		}
 
 		curr, ok := sg.hashToState[currHash]
 		if !ok {
@@ -85,52 +193,115 @@ func (c Checker) runTransitions() (StateGraph, *Violation) {
 		}
 		log.Debugf("curr %v", curr)
 
-		nextStateHashSet := make(map[stateHash]interface{})
-		for _, namTran := range c.NamedTransitions {
-			statesAfterTransition := namTran.Transition(curr)
-			if len(statesAfterTransition) == 0 {
-				panic(fmt.Sprintf("there are no future states after transition %+v", namTran.Name)) // Make it an error. Named transitions? Reflection?
-			}
-			for _, next := range statesAfterTransition {
-				nextHash := GetHash(next)
-				nextStateHashSet[nextHash] = next
-			}
-		}
-
 		nextStateHashes := []stateHash{}
-		for nextHash, next := range nextStateHashSet {
+		nextStates := c.nextStates(curr)
+		progress.stats.StatesFound += len(nextStates)
+		for nextHash, ns := range nextStates {
+			next := ns.state
+			_, seen := sg.hashToState[nextHash]
 			sg.hashToState[nextHash] = next
 			log.Debugf("%v -> %v", curr, next)
 
 			nextStateHashes = append(nextStateHashes, nextHash)
-
-			for _, namInv := range c.NamedInvariants {
-				if !namInv.Inv(curr, next) {
-					path := []interface{}{}
-					for _, h := range findShortestPathHash(sg.hashGraph, initialStateHash, currHash) {
-						path = append(path, sg.hashToState[h])
+			sg.edgeTransitions[hashEdge{currHash, nextHash}] = ns.transitions
+			isStutter := nextHash == currHash
+			progress.stats.Coverage.countTransitions(ns.transitions, isStutter, !seen)
+
+			invIndex := c.findViolatedInvariant(curr, next)
+			if invIndex == -1 {
+				progress.stats.Coverage.countInvariants(len(c.NamedInvariants), isStutter)
+			} else {
+				progress.stats.Coverage.countInvariants(invIndex+1, isStutter)
+				// The graph explored so far might not have the cheapest path yet, so search for it anew. The search takes
+				// only the steps that hold all the invariants, so the trace does not violate another invariant earlier.
+				trace, found := c.findCheapestTrace(func(h stateHash, _ interface{}) bool {
+					return h == currHash
+				}, func(fromHash stateHash, from interface{}, _ stateHash, to interface{}) bool {
+					if _, expanded := sg.hashGraph[fromHash]; c.MaxDepth > 0 && !expanded {
+						// Do not search beyond the explored states, there might be too many states past the depth limit.
+						return false
 					}
-					violation := Violation{
-						Inv:              &namInv,
-						Curr:             curr,
-						Next:             next,
-						Path:             path,
-						namedTransitions: c.NamedTransitions,
-					}
-					return sg, &violation
+					return c.findViolatedInvariant(from, to) == -1
+				})
+				if !found {
+					panic(fmt.Sprintf("RATS! state not reachable: %v", curr))
+				}
+				path := trace.States
+				violation := Violation{
+					Inv:              &c.NamedInvariants[invIndex],
+					Curr:             curr,
+					Next:             next,
+					Path:             path,
+					namedTransitions: c.NamedTransitions,
 				}
+				return sg, finalStats(), &violation
 			}
 
-			if _, ok := sg.hashGraph[nextHash]; !ok {
+			if _, ok := sg.hashGraph[nextHash]; !ok || c.MaxDepth > 0 {
 				// Minor optimization. Do not fill backlog with the states that will be skipped immediately at the beginning of the loop.
-				backlog = append(backlog, nextHash)
+				backlog = append(backlog, backlogItem{nextHash, currItem.depth + 1})
 			}
 		}
 		sg.hashGraph[currHash] = nextStateHashes
 	}
 
 	log.Debugf("all hashshes: %d", len(sg.hashToState))
-	return sg, nil
+	return sg, finalStats(), nil
+}
+
+type nextState struct {
+	state interface{}
+	// transitions are indices of the transitions that lead to the state.
+	transitions []int
+}
+
+// nextStates returns all the states reachable from curr with a single transition, keyed by their hash.
+func (c Checker) nextStates(curr interface{}) map[stateHash]*nextState {
+	nextStateHashSet := make(map[stateHash]*nextState)
+	for i, namTran := range c.NamedTransitions {
+		statesAfterTransition := namTran.Transition(curr)
+		if len(statesAfterTransition) == 0 {
+			panic(fmt.Sprintf("there are no future states after transition %+v", namTran.Name)) // Make it an error. Named transitions? Reflection?
+		}
+		for _, next := range statesAfterTransition {
+			nextHash := GetHash(next)
+			ns, ok := nextStateHashSet[nextHash]
+			if !ok {
+				ns = &nextState{state: next}
+				nextStateHashSet[nextHash] = ns
+			}
+			if len(ns.transitions) == 0 || ns.transitions[len(ns.transitions)-1] != i {
+				ns.transitions = append(ns.transitions, i)
+			}
+		}
+	}
+	return nextStateHashSet
+}
+
+// stepCost returns the cost of the cheapest of the transitions leading to the next state, and the index of that
+// transition.
+func (c Checker) stepCost(curr interface{}, ns *nextState) (int, int) {
+	bestCost, bestIndex := 0, -1
+	for _, i := range ns.transitions {
+		cost := 1
+		if c.NamedTransitions[i].Cost != nil {
+			cost = c.NamedTransitions[i].Cost(curr, ns.state)
+		}
+		if bestIndex == -1 || cost < bestCost {
+			bestCost, bestIndex = cost, i
+		}
+	}
+	return bestCost, bestIndex
+}
+
+// findViolatedInvariant returns index of the first invariant that does not hold for the curr -> next step, or -1.
+func (c Checker) findViolatedInvariant(curr, next interface{}) int {
+	for i, namInv := range c.NamedInvariants {
+		if !namInv.Inv(curr, next) {
+			return i
+		}
+	}
+	return -1
 }
 
 func (c Checker) runTemporalChecks(g StateGraph) *Violation {
@@ -148,27 +319,6 @@ func (c Checker) runTemporalChecks(g StateGraph) *Violation {
 	return nil
 }
 
-func findShortestPathHash(stateHashTransitionMap map[stateHash][]stateHash, start, end stateHash) []stateHash {
-	verStart := spa.Vertex(start)
-	verEnd := spa.Vertex(end)
-
-	getNext := func(currVertex spa.Vertex) []spa.Vertex {
-		nextVertices := []spa.Vertex{}
-		for _, nextStateHash := range stateHashTransitionMap[stateHash(currVertex)] {
-			nextVertices = append(nextVertices, spa.Vertex(nextStateHash))
-		}
-		return nextVertices
-	}
-
-	hashPath := []stateHash{}
-
-	for _, h := range spa.Find(verStart, verEnd, getNext) {
-		hashPath = append(hashPath, stateHash(h))
-	}
-
-	return hashPath
-}
-
 type Analysis struct {
 	Violation              *Violation
 	stateHashTransitionMap map[stateHash][]stateHash
@@ -181,6 +331,8 @@ type Violation struct {
 	Curr, Next       interface{}
 	Path             []interface{}
 	namedTransitions []NamedTransition
+	// transitions are the names of the transitions of the trace, if the violation was decoded from JSON.
+	transitions []string
 }
 
 func (v Violation) String() string {
@@ -209,7 +361,7 @@ func (v Violation) String() string {
 				if exp, ok := v.findTransitionMatchingStates(v.Path[i], v.Path[i+1]); ok {
 					s += fmt.Sprintf("%d->%d\t%s\n", i, i+1, exp)
 				} else {
-					s += fmt.Sprintf("%d->%d\tRATS! Illegal transition\n", i, i+1)
+					s += fmt.Sprintf("%d->%d\t%s\n", i, i+1, IllegalTransition)
 				}
 			}
 		}
@@ -220,6 +372,67 @@ func (v Violation) String() string {
 	return s
 }
 
+// IllegalTransition is the name of a step of a violation that none of the transitions reproduces, e.g. in a violation
+// saved before the model changed.
+const IllegalTransition = "RATS! Illegal transition"
+
+// Trace returns the path of the violation with the names of the transitions. For an invariant, the path ends with the
+// Next state of the violating step. A step that none of the transitions reproduces is named IllegalTransition.
+func (v Violation) Trace() Trace {
+	states := append([]interface{}{}, v.Path...)
+	if v.Inv != nil && v.Next != nil {
+		states = append(states, v.Next)
+	}
+	t := Trace{States: states}
+	if v.transitions != nil {
+		t.Transitions = v.transitions
+		return t
+	}
+	for i := 0; i+1 < len(states); i++ {
+		name, ok := v.findTransitionMatchingStates(states[i], states[i+1])
+		if !ok {
+			name = IllegalTransition
+		}
+		t.Transitions = append(t.Transitions, name)
+	}
+	return t
+}
+
+// Format shows the trace of the violation with only the fields that changed at each step, see Trace.Format.
+func (v Violation) Format(f TraceFormat) string {
+	s := ""
+	if v.Inv != nil {
+		s += fmt.Sprintf("Violation of invariant: %s\n", v.Inv.Name)
+	}
+	if v.Prop != nil {
+		s += fmt.Sprintf("Violation of property: %s\n", v.Prop.Name)
+	}
+	if f == FormatMarkdown {
+		s += "\n"
+	}
+	s += v.Trace().Format(f)
+	if loopIndex := v.LoopIndex(); loopIndex != -1 {
+		s += fmt.Sprintf("(back to %d)\n", loopIndex)
+	}
+	return s
+}
+
+// LoopIndex returns the index of the path where the last state of the path was seen before, i.e. where the
+// counterexample of a temporal property loops back to, or -1 if the path has no loop.
+func (v Violation) LoopIndex() int {
+	if len(v.Path) == 0 {
+		return -1
+	}
+	lastHash := GetHash(v.Path[len(v.Path)-1])
+	loopIndex := -1
+	for i, stateOnPath := range v.Path[:len(v.Path)-1] {
+		if GetHash(stateOnPath) == lastHash {
+			loopIndex = i
+		}
+	}
+	return loopIndex
+}
+
 func (v Violation) findTransitionMatchingStates(curr, next interface{}) (string, bool) {
 	for _, t := range v.namedTransitions {
 		for _, tentativeNext := range t.Transition(curr) {
@@ -0,0 +1,94 @@
+package state
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+const defaultProgressInterval = time.Second
+
+// Stats are the statistics of a checker run. During the run they are reported with Checker.Progress, at the end they
+// are returned by Checker.Run.
+type Stats struct {
+	// StatesFound is the number of next states of the explored states, including the states already seen.
+	StatesFound int
+	// DistinctStates is the number of distinct states found.
+	DistinctStates int
+	// QueueLength is the number of states waiting to be explored.
+	QueueLength int
+	// Depth is the depth of the currently explored state. At the end of the run it is the maximum depth reached.
+	Depth int
+	// StatesPerSecond is StatesFound divided by the time elapsed since the start.
+	StatesPerSecond float64
+	// MemoryUsed is the number of bytes of the allocated heap objects.
+	MemoryUsed uint64
+	// Elapsed is the time since the start of the run.
+	Elapsed time.Duration
+	// Coverage tells how often the transitions and the invariants did something.
+	Coverage Coverage
+	// Warnings are about the transitions, invariants and properties that do not check anything, e.g. an initial
+	// condition of a temporal property that matches no state. Set only at the end of a run without violation.
+	Warnings []string
+	// Diameter is the length of the longest of the shortest paths from the initial state, i.e. the number of BFS
+	// levels minus one. Set only at the end of the run.
+	Diameter int
+	// DepthLimitReached is true if some states were not expanded because of Checker.MaxDepth.
+	DepthLimitReached bool
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("states found: %d, distinct: %d, queue: %d, depth: %d, diameter: %d, states/s: %.0f, memory: %d MiB, elapsed: %s",
+		s.StatesFound, s.DistinctStates, s.QueueLength, s.Depth, s.Diameter, s.StatesPerSecond, s.MemoryUsed/(1<<20), s.Elapsed.Round(time.Millisecond))
+}
+
+// progressReporter keeps the statistics of the run and calls Checker.Progress not more often than the interval.
+type progressReporter struct {
+	stats      Stats
+	start      time.Time
+	lastReport time.Time
+	interval   time.Duration
+	progress   func(Stats)
+}
+
+func newProgressReporter(c Checker) *progressReporter {
+	interval := c.ProgressInterval
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	now := time.Now()
+	return &progressReporter{
+		stats:      Stats{Coverage: newCoverage(c)},
+		start:      now,
+		lastReport: now,
+		interval:   interval,
+		progress:   c.Progress,
+	}
+}
+
+// maybeReport calls Progress if the interval passed since the last report.
+func (r *progressReporter) maybeReport() {
+	if r.progress == nil {
+		return
+	}
+	now := time.Now()
+	if now.Sub(r.lastReport) < r.interval {
+		return
+	}
+	r.lastReport = now
+	r.progress(r.snapshot())
+}
+
+// snapshot returns the current statistics with the time and memory filled in.
+func (r *progressReporter) snapshot() Stats {
+	s := r.stats
+	s.Coverage = s.Coverage.copy()
+	s.Elapsed = time.Since(r.start)
+	if secs := s.Elapsed.Seconds(); secs > 0 {
+		s.StatesPerSecond = float64(s.StatesFound) / secs
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	s.MemoryUsed = m.HeapAlloc
+	return s
+}
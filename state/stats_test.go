@@ -0,0 +1,97 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStats(t *testing.T) {
+	checker := getCountersChecker(5)
+	reported := []Stats{}
+	checker.Progress = func(s Stats) {
+		reported = append(reported, s)
+	}
+	checker.ProgressInterval = 1
+	_, stats, violation := checker.Run()
+	assert.Nil(t, violation)
+	assert.Equal(t, 25, stats.DistinctStates)
+	assert.Equal(t, 75, stats.StatesFound) // Managed adds the stuttering step.
+	assert.Equal(t, 0, stats.QueueLength)
+	assert.Equal(t, 8, stats.Diameter)
+	assert.GreaterOrEqual(t, stats.Depth, stats.Diameter)
+	assert.NotZero(t, stats.MemoryUsed)
+	assert.NotEmpty(t, reported)
+	for _, s := range reported {
+		assert.LessOrEqual(t, s.DistinctStates, 25)
+		assert.Zero(t, s.Diameter)
+	}
+}
+
+func TestRunBFS(t *testing.T) {
+	c := getCountersChecker(3)
+	c.Strategy = BFS
+	g, stats, violation := c.Run()
+	assert.Nil(t, violation)
+	assert.Equal(t, 9, g.NumStates())
+	// With BFS the deepest explored state is as deep as the diameter.
+	assert.Equal(t, stats.Diameter, stats.Depth)
+	assert.False(t, stats.DepthLimitReached)
+}
+
+func TestRunMaxDepth(t *testing.T) {
+	for _, strategy := range []SearchStrategy{DFS, BFS} {
+		c := getCountersChecker(3)
+		c.Strategy = strategy
+		c.MaxDepth = 2
+		c.NamedProperties = []NamedTemporalProperty{{
+			Name: "Never",
+			Property: TemporalProperty{
+				Prop:     CheckReachesAndStays,
+				Initial:  StateEquals(counters{}),
+				Terminal: func(interface{}) bool { return false },
+			},
+		}}
+		g, stats, violation := c.Run()
+		assert.Nil(t, violation)
+		assert.True(t, stats.DepthLimitReached)
+		assert.ElementsMatch(t, []interface{}{
+			counters{}, counters{A: 1}, counters{B: 1}, counters{A: 2}, counters{A: 1, B: 1}, counters{B: 2},
+		}, g.States())
+		assert.Contains(t, stats.Warnings, "depth limit 2 reached, the temporal properties were not checked")
+	}
+}
+
+// getEdgesChecker is a checker of the graph with the states being ints. The states without the edges stutter.
+func getEdgesChecker(edges map[int][]int) Checker {
+	return Checker{
+		InitialState: 0,
+		NamedTransitions: []NamedTransition{{
+			Name: "Step",
+			Transition: func(curr interface{}) []interface{} {
+				next := []interface{}{}
+				for _, n := range edges[curr.(int)] {
+					next = append(next, n)
+				}
+				if len(next) == 0 {
+					next = append(next, curr)
+				}
+				return next
+			},
+		}},
+	}
+}
+
+func TestRunMaxDepthShortcut(t *testing.T) {
+	// The state 3 is at depth 1, but DFS might reach it first at depth 3, through 1 and 2.
+	edges := map[int][]int{0: {1, 3}, 1: {2}, 2: {3}, 3: {4}, 4: {5}, 5: {6}}
+	// Repeated, since the order of the exploration depends on the order of the maps.
+	for i := 0; i < 20; i++ {
+		c := getEdgesChecker(edges)
+		c.MaxDepth = 4
+		g, stats, violation := c.Run()
+		assert.Nil(t, violation)
+		assert.ElementsMatch(t, []interface{}{0, 1, 2, 3, 4, 5, 6}, g.States())
+		assert.True(t, stats.DepthLimitReached)
+	}
+}
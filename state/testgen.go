@@ -0,0 +1,115 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jakub-m/formaggo/graph"
+)
+
+// TraceCoverage tells what the generated test traces must cover.
+type TraceCoverage int
+
+const (
+	// CoverEdges covers every step between two different states. The stuttering steps are not covered.
+	CoverEdges TraceCoverage = iota
+	// CoverStates covers every state.
+	CoverStates
+)
+
+// TestTraces generates traces that together cover every edge or every state of the graph, to be used as tests of the
+// real implementation. Each trace starts from the initial state. A trace is extended greedily with the shortest walk
+// to the closest uncovered edge (state), and a new trace is started when nothing uncovered is reachable anymore. The
+// result is short, but not necessarily as short as an optimal Chinese postman tour.
+func (g StateGraph) TestTraces(coverage TraceCoverage) []Trace {
+	coveredStates := make(map[stateHash]bool)
+	coveredEdges := make(map[hashEdge]bool)
+	remaining := 0
+	for h, nextHashes := range g.hashGraph {
+		for _, next := range nextHashes {
+			if next != h {
+				remaining++
+			}
+		}
+	}
+	if coverage == CoverStates {
+		remaining = len(g.hashToState)
+	}
+	cover := func(path []stateHash) {
+		for i, h := range path {
+			if !coveredStates[h] {
+				coveredStates[h] = true
+				if coverage == CoverStates {
+					remaining--
+				}
+			}
+			if i == 0 {
+				continue
+			}
+			e := hashEdge{path[i-1], h}
+			if e.from != e.to && !coveredEdges[e] {
+				coveredEdges[e] = true
+				if coverage == CoverEdges {
+					remaining--
+				}
+			}
+		}
+	}
+	uncoveredEdge := func(h stateHash) (stateHash, bool) {
+		for _, next := range g.hashGraph[h] {
+			if next != h && !coveredEdges[hashEdge{h, next}] {
+				return next, true
+			}
+		}
+		return 0, false
+	}
+	isTarget := func(v graph.Vertex) bool {
+		if coverage == CoverStates {
+			return !coveredStates[v.(stateHash)]
+		}
+		_, ok := uncoveredEdge(v.(stateHash))
+		return ok
+	}
+	getNext := graph.Unweighted(func(v graph.Vertex) []graph.Vertex {
+		next := []graph.Vertex{}
+		for _, h := range g.hashGraph[v.(stateHash)] {
+			next = append(next, h)
+		}
+		return next
+	})
+
+	traces := []Trace{}
+	for remaining > 0 {
+		path := []stateHash{g.initialHash}
+		before := remaining
+		cover(path)
+		for remaining > 0 {
+			p := graph.CheapestPath(path[len(path)-1], isTarget, getNext)
+			if !p.Found {
+				break
+			}
+			for _, v := range p.Vertices[1:] {
+				path = append(path, v.(stateHash))
+			}
+			if coverage == CoverEdges {
+				next, _ := uncoveredEdge(path[len(path)-1])
+				path = append(path, next)
+			}
+			cover(path)
+		}
+		if remaining == before {
+			panic(fmt.Sprintf("RATS! %d edges or states not reachable from the initial state", remaining))
+		}
+		traces = append(traces, g.hashPathToTrace(path))
+	}
+	return traces
+}
+
+// ExportTracesToJSON writes the traces as a JSON array of objects with the "states" and "transitions" fields, e.g.
+// for the test harnesses written in other languages.
+func ExportTracesToJSON(w io.Writer, traces []Trace) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(traces)
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestTracesCoverEdges(t *testing.T) {
+	g, _, _ := getRingChecker().Run()
+	traces := g.TestTraces(CoverEdges)
+	covered := [][2]interface{}{}
+	for _, trace := range traces {
+		assert.Equal(t, 0, trace.States[0])
+		for i, name := range trace.Transitions {
+			from, to := trace.States[i], trace.States[i+1]
+			assert.Contains(t, g.edge(GetHash(from), GetHash(to)).Transitions, name)
+			covered = append(covered, [2]interface{}{from, to})
+		}
+	}
+	for _, e := range [][2]interface{}{{0, 1}, {1, 2}, {2, 0}, {2, 3}, {3, 4}} {
+		assert.Contains(t, covered, e)
+	}
+}
+
+func TestTestTracesCoverStates(t *testing.T) {
+	g, _, _ := getRingChecker().Run()
+	traces := g.TestTraces(CoverStates)
+	assert.Equal(t, []Trace{{
+		States:      []interface{}{0, 1, 2, 3, 4},
+		Transitions: []string{"Ring", "Ring", "Escape", "Escape"},
+	}}, traces)
+}
+
+func TestExportTracesToJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, ExportTracesToJSON(&buf, []Trace{{
+		States:      []interface{}{counters{}, counters{A: 1}},
+		Transitions: []string{"IncA"},
+	}}))
+	var decoded []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, []map[string]interface{}{{
+		"states":      []interface{}{map[string]interface{}{"A": 0.0, "B": 0.0}, map[string]interface{}{"A": 1.0, "B": 0.0}},
+		"transitions": []interface{}{"IncA"},
+	}}, decoded)
+}
@@ -0,0 +1,192 @@
+package state
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// maxProbedStates limits the number of reachable states used to probe the invariants.
+const maxProbedStates = 100
+
+// checkVacuity returns warnings about the properties and invariants that hold, but do not check anything. E.g. a
+// StateEquals initial condition that never matches makes a temporal property pass trivially.
+func (c Checker) checkVacuity(g StateGraph) []string {
+	warnings := []string{}
+	for _, prop := range c.NamedProperties {
+		initialMatches, terminalHolds := false, false
+		for _, s := range g.hashToState {
+			initialMatches = initialMatches || prop.Property.Initial(s)
+			terminalHolds = terminalHolds || prop.Property.Terminal(s)
+			if initialMatches && terminalHolds {
+				break
+			}
+		}
+		if !initialMatches {
+			warnings = append(warnings, fmt.Sprintf("initial condition of property %s matches no state", prop.Name))
+		}
+		if !terminalHolds {
+			warnings = append(warnings, fmt.Sprintf("terminal condition of property %s never holds", prop.Name))
+		}
+	}
+
+	if len(c.NamedInvariants) == 0 {
+		return warnings
+	}
+	probed := sampleStates(g, maxProbedStates)
+	for _, namInv := range c.NamedInvariants {
+		if isTriviallyTrue(namInv.Inv, probed) {
+			warnings = append(warnings, fmt.Sprintf("invariant %s held on all the probes with %d sampled states, mutated and paired, it may be trivially true", namInv.Name, len(probed)))
+		}
+	}
+	return warnings
+}
+
+// sampleStates returns at most n states of the graph, evenly spread over the (sorted) hashes.
+func sampleStates(g StateGraph, n int) []interface{} {
+	hashes := g.sortedHashes()
+	step := 1
+	if len(hashes) > n {
+		step = len(hashes) / n
+	}
+	states := []interface{}{}
+	for i := 0; i < len(hashes) && len(states) < n; i += step {
+		states = append(states, g.hashToState[hashes[i]])
+	}
+	return states
+}
+
+// isTriviallyTrue tells if the invariant holds not only on the reachable states, but also on the probe steps made of
+// the states with mutated fields, and of the pairs of the states. The pairs catch the invariants of the steps changing
+// several fields at once, which a single mutated field never does. An invariant that holds regardless of the values
+// does not check anything. It is a heuristic, since only some of the values are probed. If there is nothing to mutate,
+// the invariant is not considered trivial.
+func isTriviallyTrue(inv Invariant, states []interface{}) bool {
+	probed := false
+	for _, s := range states {
+		for _, m := range mutateState(s) {
+			probed = true
+			if !invariantHolds(inv, s, m) || !invariantHolds(inv, m, s) || !invariantHolds(inv, m, m) {
+				return false
+			}
+		}
+	}
+	if !probed {
+		return false
+	}
+	for _, curr := range states {
+		for _, next := range states {
+			if !invariantHolds(inv, curr, next) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// invariantHolds evaluates the invariant, treating a panic (e.g. an index out of range for a mutated value) as false.
+func invariantHolds(inv Invariant, curr, next interface{}) (holds bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			holds = false
+		}
+	}()
+	return inv(curr, next)
+}
+
+// mutateState returns copies of the state, each with a single primitive field changed. Only the exported fields of
+// the structs and the elements of the arrays are mutated, since the slices, maps and pointers are shared between the
+// copies.
+func mutateState(s interface{}) []interface{} {
+	v := reflect.ValueOf(s)
+	if !v.IsValid() {
+		return nil
+	}
+	mutations := []interface{}{}
+	for _, path := range primitivePaths(v, nil) {
+		for _, newValue := range mutateValue(followPath(v, path)) {
+			copied := reflect.New(v.Type()).Elem()
+			copied.Set(v)
+			followPath(copied, path).Set(newValue)
+			mutations = append(mutations, copied.Interface())
+		}
+	}
+	return mutations
+}
+
+// primitivePaths returns paths (indices of struct fields and array elements) to the primitive values within v.
+func primitivePaths(v reflect.Value, path []int) [][]int {
+	paths := [][]int{}
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				continue // private
+			}
+			paths = append(paths, primitivePaths(v.Field(i), appendPath(path, i))...)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			paths = append(paths, primitivePaths(v.Index(i), appendPath(path, i))...)
+		}
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func appendPath(path []int, i int) []int {
+	return append(append([]int{}, path...), i)
+}
+
+func followPath(v reflect.Value, path []int) reflect.Value {
+	for _, i := range path {
+		if v.Kind() == reflect.Struct {
+			v = v.Field(i)
+		} else {
+			v = v.Index(i)
+		}
+	}
+	return v
+}
+
+// mutateValue returns values of the same type, different than v.
+func mutateValue(v reflect.Value) []reflect.Value {
+	values := []reflect.Value{}
+	add := func(set func(reflect.Value)) {
+		nv := reflect.New(v.Type()).Elem()
+		set(nv)
+		if nv.Interface() != v.Interface() {
+			values = append(values, nv)
+		}
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		add(func(nv reflect.Value) { nv.SetBool(!v.Bool()) })
+	case reflect.String:
+		add(func(nv reflect.Value) { nv.SetString("") })
+		add(func(nv reflect.Value) { nv.SetString(v.String() + "?") })
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for _, x := range []int64{0, -1, v.Int() - 1, v.Int() + 1, 1 << 20, -1 << 20} {
+			if !v.OverflowInt(x) {
+				x := x
+				add(func(nv reflect.Value) { nv.SetInt(x) })
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		for _, x := range []uint64{0, v.Uint() - 1, v.Uint() + 1, 1 << 20} {
+			if !v.OverflowUint(x) {
+				x := x
+				add(func(nv reflect.Value) { nv.SetUint(x) })
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		for _, x := range []float64{0, -1, v.Float() - 1, v.Float() + 1} {
+			x := x
+			add(func(nv reflect.Value) { nv.SetFloat(x) })
+		}
+	}
+	return values
+}
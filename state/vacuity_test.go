@@ -0,0 +1,75 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVacuityWarnings(t *testing.T) {
+	checker := getCountersChecker(3)
+	checker.NamedInvariants = []NamedInvariant{
+		{
+			Name: "Tautology",
+			Inv: func(curr, next interface{}) bool {
+				c := curr.(counters)
+				return c.A >= 0 || c.A < 0
+			},
+		},
+		{
+			Name: "ALimited",
+			Inv: func(curr, next interface{}) bool {
+				return curr.(counters).A < 3
+			},
+		},
+	}
+	checker.NamedProperties = []NamedTemporalProperty{
+		{
+			Name: "NeverStarts",
+			Property: TemporalProperty{
+				Prop:     CheckReachesAndStays,
+				Initial:  StateEquals(counters{A: 10}),
+				Terminal: func(interface{}) bool { return false },
+			},
+		},
+	}
+	_, stats, violation := checker.Run()
+	assert.Nil(t, violation)
+	assert.Equal(t, []string{
+		"initial condition of property NeverStarts matches no state",
+		"terminal condition of property NeverStarts never holds",
+		"invariant Tautology held on all the probes with 9 sampled states, mutated and paired, it may be trivially true",
+	}, stats.Warnings)
+}
+
+func TestVacuityInvariantOfTwoFields(t *testing.T) {
+	checker := getCountersChecker(3)
+	checker.NamedInvariants = []NamedInvariant{{
+		// Holds on every step changing a single field, like all the steps of the model.
+		Name: "SumKeptWhenBothChange",
+		Inv: func(curr, next interface{}) bool {
+			c, n := curr.(counters), next.(counters)
+			if c.A != n.A && c.B != n.B {
+				return c.A+c.B == n.A+n.B
+			}
+			return true
+		},
+	}}
+	_, stats, violation := checker.Run()
+	assert.Nil(t, violation)
+	assert.Empty(t, stats.Warnings)
+}
+
+func TestMutateState(t *testing.T) {
+	type inner struct {
+		Flag    bool
+		private int
+	}
+	s := struct {
+		Arr   [2]inner
+		Slice []int
+	}{}
+	assert.Len(t, mutateState(s), 2)
+	assert.Len(t, mutateState(uint8(255)), 2)
+	assert.Len(t, mutateState("foo"), 2)
+}
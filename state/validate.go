@@ -0,0 +1,123 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LogEntry is a single step observed in a log of a real system. Both fields are optional.
+type LogEntry struct {
+	// Action is the name of the transition of the step. Empty matches any transition.
+	Action string
+	// Observed tells if the state after the step matches what was logged, e.g. only some of the fields. Nil matches
+	// any state.
+	Observed StateCondition
+}
+
+// LogValidation is the result of Checker.ValidateLog.
+type LogValidation struct {
+	// Valid is true if the whole log is a behaviour of the model.
+	Valid bool
+	// Diverged is the index of the first entry that no execution of the model matches, or -1 if the log is valid.
+	Diverged int
+	// Trace is an execution of the model matching the log, up to the divergence.
+	Trace Trace
+	// Candidates are the states the model can be in just before the diverging entry, ordered by hash.
+	Candidates []interface{}
+}
+
+func (v LogValidation) String() string {
+	if v.Valid {
+		return "Log matches the model\n" + v.Trace.String()
+	}
+	s := fmt.Sprintf("Log diverges from the model at entry %d, after:\n%s", v.Diverged, v.Trace)
+	s += fmt.Sprintf("possible states before the entry: %d\n", len(v.Candidates))
+	for _, c := range v.Candidates {
+		s += fmt.Sprintf("\t%v\n", c)
+	}
+	return s
+}
+
+// logStep is how a state was reached when following the log.
+type logStep struct {
+	state      interface{}
+	parent     stateHash
+	transition string
+}
+
+// ValidateLog checks if the log is a possible behaviour of the model. Each entry is a step, the first one starting from
+// the initial state. Since the entries can be partial, the model can be in many states after each entry, and all of
+// them are followed. The invariants and properties are not checked.
+func (c Checker) ValidateLog(entries []LogEntry) LogValidation {
+	initialHash := GetHash(c.InitialState)
+	levels := []map[stateHash]logStep{{initialHash: {state: c.InitialState}}}
+	for i, entry := range entries {
+		curr := levels[len(levels)-1]
+		next := make(map[stateHash]logStep)
+		for _, h := range sortedStepHashes(curr) {
+			for nextHash, ns := range c.nextStates(curr[h].state) {
+				if _, ok := next[nextHash]; ok {
+					continue
+				}
+				if entry.Observed != nil && !entry.Observed(ns.state) {
+					continue
+				}
+				if name, ok := c.matchingTransition(ns, entry.Action); ok {
+					next[nextHash] = logStep{state: ns.state, parent: h, transition: name}
+				}
+			}
+		}
+		if len(next) == 0 {
+			candidates := []interface{}{}
+			for _, h := range sortedStepHashes(curr) {
+				candidates = append(candidates, curr[h].state)
+			}
+			return LogValidation{Diverged: i, Trace: traceFromLogSteps(levels), Candidates: candidates}
+		}
+		levels = append(levels, next)
+	}
+	return LogValidation{Valid: true, Diverged: -1, Trace: traceFromLogSteps(levels)}
+}
+
+// matchingTransition returns the name of the first of the transitions leading to the next state that has the name of
+// the action.
+func (c Checker) matchingTransition(ns *nextState, action string) (string, bool) {
+	for _, i := range ns.transitions {
+		name := c.NamedTransitions[i].Name
+		if action == "" || action == name {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// traceFromLogSteps follows the parents back from the state with the lowest hash of the last level.
+func traceFromLogSteps(levels []map[stateHash]logStep) Trace {
+	last := len(levels) - 1
+	h := sortedStepHashes(levels[last])[0]
+	t := Trace{
+		States:      make([]interface{}, last+1),
+		Transitions: make([]string, last),
+	}
+	for i := last; i >= 0; i-- {
+		step := levels[i][h]
+		t.States[i] = step.state
+		if i > 0 {
+			t.Transitions[i-1] = step.transition
+		}
+		h = step.parent
+	}
+	if last == 0 {
+		t.Transitions = nil
+	}
+	return t
+}
+
+func sortedStepHashes(steps map[stateHash]logStep) []stateHash {
+	hashes := []stateHash{}
+	for h := range steps {
+		hashes = append(hashes, h)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	return hashes
+}
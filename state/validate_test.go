@@ -0,0 +1,51 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLog(t *testing.T) {
+	c := getCountersChecker(3)
+	v := c.ValidateLog([]LogEntry{
+		{Action: "IncA", Observed: func(s interface{}) bool { return s.(counters).A == 1 }},
+		{Action: "IncB"},
+		{Observed: func(s interface{}) bool { return s == counters{A: 2, B: 1} }},
+	})
+	assert.True(t, v.Valid)
+	assert.Equal(t, -1, v.Diverged)
+	assert.Equal(t, []interface{}{counters{}, counters{A: 1}, counters{A: 1, B: 1}, counters{A: 2, B: 1}}, v.Trace.States)
+	assert.Equal(t, []string{"IncA", "IncB", "IncA"}, v.Trace.Transitions)
+}
+
+func TestValidateLogDiverges(t *testing.T) {
+	c := getCountersChecker(3)
+	v := c.ValidateLog([]LogEntry{
+		{Action: "IncA"},
+		{Observed: func(s interface{}) bool { return s.(counters).B == 2 }},
+		{Action: "IncB"},
+	})
+	assert.False(t, v.Valid)
+	assert.Equal(t, 1, v.Diverged)
+	// IncA can stutter, so the model can be in either of the states.
+	assert.ElementsMatch(t, []interface{}{counters{}, counters{A: 1}}, v.Candidates)
+	assert.Len(t, v.Trace.States, 2)
+	assert.Equal(t, []string{"IncA"}, v.Trace.Transitions)
+	assert.Contains(t, v.String(), "diverges from the model at entry 1")
+	assert.Contains(t, v.String(), "possible states before the entry: 2\n")
+	assert.Contains(t, v.String(), "\t{0 0}\n")
+	assert.Contains(t, v.String(), "\t{1 0}\n")
+}
+
+func TestValidateLogUnknownAction(t *testing.T) {
+	v := getCountersChecker(3).ValidateLog([]LogEntry{{Action: "IncC"}})
+	assert.False(t, v.Valid)
+	assert.Equal(t, 0, v.Diverged)
+	assert.Equal(t, []interface{}{counters{}}, v.Trace.States)
+	assert.Equal(t, []interface{}{counters{}}, v.Candidates)
+	assert.Equal(t, "Log diverges from the model at entry 0, after:\n"+
+		"0\t{0 0}\n"+
+		"possible states before the entry: 1\n"+
+		"\t{0 0}\n", v.String())
+}
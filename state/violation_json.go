@@ -0,0 +1,142 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+const (
+	violationKindInvariant = "invariant"
+	violationKindProperty  = "property"
+)
+
+// jsonViolation is how the violation is stored in JSON.
+type jsonViolation struct {
+	// Kind is "invariant" or "property".
+	Kind string `json:"kind"`
+	// Name is the name of the invariant or the property.
+	Name string `json:"name"`
+	// States are the states of the trace. For an invariant, the last step violates the invariant.
+	States      []json.RawMessage `json:"states"`
+	Transitions []string          `json:"transitions"`
+	// Loop is the index of the state the last state of the trace loops back to, or -1.
+	Loop int `json:"loop"`
+}
+
+// MarshalJSON writes the violation as an object with the kind ("invariant" or "property"), the name, the states and
+// the transition names of the trace, and the loop index. The states are marshalled with encoding/json. Use
+// Checker.UnmarshalViolation to read it back.
+func (v Violation) MarshalJSON() ([]byte, error) {
+	jv := jsonViolation{Loop: v.LoopIndex()}
+	switch {
+	case v.Inv != nil:
+		jv.Kind, jv.Name = violationKindInvariant, v.Inv.Name
+	case v.Prop != nil:
+		jv.Kind, jv.Name = violationKindProperty, v.Prop.Name
+	default:
+		return nil, fmt.Errorf("violation has neither invariant nor property")
+	}
+	trace := v.Trace()
+	for _, s := range trace.States {
+		b, err := json.Marshal(s)
+		if err != nil {
+			return nil, err
+		}
+		jv.States = append(jv.States, b)
+	}
+	jv.Transitions = trace.Transitions
+	if jv.Transitions == nil {
+		jv.Transitions = []string{}
+	}
+	return json.Marshal(jv)
+}
+
+// UnmarshalViolation reads a violation written with Violation.MarshalJSON. The states are unmarshalled into values of
+// the type of the initial state, and the invariant or property is looked up by name in the checker.
+func (c Checker) UnmarshalViolation(data []byte) (*Violation, error) {
+	var jv jsonViolation
+	if err := json.Unmarshal(data, &jv); err != nil {
+		return nil, err
+	}
+	if len(jv.Transitions)+1 != len(jv.States) {
+		return nil, fmt.Errorf("violation has %d states and %d transitions", len(jv.States), len(jv.Transitions))
+	}
+	stateType := reflect.TypeOf(c.InitialState)
+	states := []interface{}{}
+	for i, raw := range jv.States {
+		s := reflect.New(stateType)
+		if err := json.Unmarshal(raw, s.Interface()); err != nil {
+			return nil, fmt.Errorf("state %d: %w", i, err)
+		}
+		states = append(states, s.Elem().Interface())
+	}
+
+	v := &Violation{namedTransitions: c.NamedTransitions, transitions: jv.Transitions}
+	switch jv.Kind {
+	case violationKindInvariant:
+		for i := range c.NamedInvariants {
+			if c.NamedInvariants[i].Name == jv.Name {
+				v.Inv = &c.NamedInvariants[i]
+			}
+		}
+		if v.Inv == nil {
+			return nil, fmt.Errorf("no invariant %s", jv.Name)
+		}
+		if len(states) < 2 {
+			return nil, fmt.Errorf("violation of invariant %s has less than 2 states", jv.Name)
+		}
+		v.Path = states[:len(states)-1]
+		v.Curr, v.Next = states[len(states)-2], states[len(states)-1]
+	case violationKindProperty:
+		for i := range c.NamedProperties {
+			if c.NamedProperties[i].Name == jv.Name {
+				v.Prop = &c.NamedProperties[i]
+			}
+		}
+		if v.Prop == nil {
+			return nil, fmt.Errorf("no property %s", jv.Name)
+		}
+		v.Path = states
+	default:
+		return nil, fmt.Errorf("unknown kind of violation: %s", jv.Kind)
+	}
+	return v, nil
+}
+
+// Replay checks that the trace is still an execution of the model: it starts at the initial state, and each step is
+// made by the named transition (or by any transition, if the name is empty). The invariants are evaluated along the
+// trace, and the first violation is returned. The error is returned if the trace is not an execution of the model.
+func (c Checker) Replay(t Trace) (*Violation, error) {
+	if len(t.States) == 0 {
+		return nil, fmt.Errorf("trace has no states")
+	}
+	if len(t.Transitions) != 0 && len(t.Transitions)+1 != len(t.States) {
+		return nil, fmt.Errorf("trace has %d states and %d transitions", len(t.States), len(t.Transitions))
+	}
+	if GetHash(t.States[0]) != GetHash(c.InitialState) {
+		return nil, fmt.Errorf("trace starts at %v, not at the initial state %v", t.States[0], c.InitialState)
+	}
+	for i := 0; i+1 < len(t.States); i++ {
+		curr, next := t.States[i], t.States[i+1]
+		ns, ok := c.nextStates(curr)[GetHash(next)]
+		if !ok {
+			return nil, fmt.Errorf("step %d->%d: no transition from %v to %v", i, i+1, curr, next)
+		}
+		if len(t.Transitions) != 0 && t.Transitions[i] != "" {
+			if _, ok := c.matchingTransition(ns, t.Transitions[i]); !ok {
+				return nil, fmt.Errorf("step %d->%d: transition %s does not lead from %v to %v", i, i+1, t.Transitions[i], curr, next)
+			}
+		}
+		if invIndex := c.findViolatedInvariant(curr, next); invIndex != -1 {
+			return &Violation{
+				Inv:              &c.NamedInvariants[invIndex],
+				Curr:             curr,
+				Next:             next,
+				Path:             append([]interface{}{}, t.States[:i+1]...),
+				namedTransitions: c.NamedTransitions,
+			}, nil
+		}
+	}
+	return nil, nil
+}
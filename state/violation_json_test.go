@@ -0,0 +1,121 @@
+package state
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getCountersCheckerWithInvariant() Checker {
+	c := getCountersChecker(3)
+	c.NamedInvariants = []NamedInvariant{{
+		Name: "ABelow2",
+		Inv: func(curr, next interface{}) bool {
+			return next.(counters).A < 2
+		},
+	}}
+	return c
+}
+
+func TestViolationJSONInvariant(t *testing.T) {
+	c := getCountersCheckerWithInvariant()
+	_, _, violation := c.Run()
+	assert.NotNil(t, violation)
+
+	data, err := json.Marshal(violation)
+	assert.NoError(t, err)
+	var generic map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &generic))
+	assert.Equal(t, "invariant", generic["kind"])
+	assert.Equal(t, "ABelow2", generic["name"])
+	assert.Equal(t, -1.0, generic["loop"])
+
+	decoded, err := c.UnmarshalViolation(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "ABelow2", decoded.Inv.Name)
+	assert.Equal(t, violation.Path, decoded.Path)
+	assert.Equal(t, violation.Next, decoded.Next)
+	assert.Equal(t, violation.Trace(), decoded.Trace())
+
+	replayed, err := c.Replay(decoded.Trace())
+	assert.NoError(t, err)
+	if assert.NotNil(t, replayed) {
+		assert.Equal(t, violation.Trace(), replayed.Trace())
+	}
+}
+
+func TestViolationJSONProperty(t *testing.T) {
+	c := getCountersChecker(3)
+	c.NamedProperties = []NamedTemporalProperty{{
+		Name: "AReachesTwo",
+		Property: TemporalProperty{
+			Prop:     CheckReachesAndStays,
+			Initial:  StateEquals(counters{}),
+			Terminal: func(s interface{}) bool { return s.(counters).A == 2 },
+		},
+	}}
+	_, _, violation := c.Run()
+	assert.NotNil(t, violation)
+	assert.NotEqual(t, -1, violation.LoopIndex())
+
+	data, err := json.Marshal(violation)
+	assert.NoError(t, err)
+	decoded, err := c.UnmarshalViolation(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "AReachesTwo", decoded.Prop.Name)
+	assert.Equal(t, violation.Path, decoded.Path)
+	assert.Equal(t, violation.LoopIndex(), decoded.LoopIndex())
+	assert.Equal(t, violation.String(), decoded.String())
+}
+
+func TestViolationNotReproducible(t *testing.T) {
+	c := getCountersCheckerWithInvariant()
+	_, _, violation := c.Run()
+	assert.NotNil(t, violation)
+	// No transition increments A by two.
+	violation.Path = []interface{}{counters{}, counters{A: 2}}
+	violation.Curr, violation.Next = counters{A: 2}, counters{A: 2}
+
+	assert.Equal(t, []string{IllegalTransition, "IncA"}, violation.Trace().Transitions)
+	assert.Contains(t, violation.Format(FormatPlain), IllegalTransition)
+	data, err := json.Marshal(violation)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), IllegalTransition)
+	_, err = c.Replay(violation.Trace())
+	assert.Error(t, err)
+}
+
+func TestUnmarshalViolationUnknownName(t *testing.T) {
+	_, err := getCountersChecker(3).UnmarshalViolation([]byte(`{"kind":"invariant","name":"Foo","states":[{"A":0,"B":0},{"A":1,"B":0}],"transitions":["IncA"],"loop":-1}`))
+	assert.EqualError(t, err, "no invariant Foo")
+}
+
+func TestReplay(t *testing.T) {
+	c := getCountersCheckerWithInvariant()
+	v, err := c.Replay(Trace{
+		States:      []interface{}{counters{}, counters{B: 1}, counters{A: 1, B: 1}},
+		Transitions: []string{"IncB", "IncA"},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	_, err = c.Replay(Trace{
+		States:      []interface{}{counters{}, counters{B: 1}},
+		Transitions: []string{"IncA"},
+	})
+	assert.EqualError(t, err, "step 0->1: transition IncA does not lead from {0 0} to {0 1}")
+
+	_, err = c.Replay(Trace{States: []interface{}{counters{}, counters{A: 2}}})
+	assert.EqualError(t, err, "step 0->1: no transition from {0 0} to {2 0}")
+
+	_, err = c.Replay(Trace{States: []interface{}{counters{A: 1}}})
+	assert.EqualError(t, err, "trace starts at {1 0}, not at the initial state {0 0}")
+
+	v, err = c.Replay(Trace{States: []interface{}{counters{}, counters{A: 1}, counters{A: 2}}})
+	assert.NoError(t, err)
+	if assert.NotNil(t, v) {
+		assert.Equal(t, "ABelow2", v.Inv.Name)
+		assert.Equal(t, counters{A: 2}, v.Next)
+	}
+}
@@ -0,0 +1,210 @@
+// Package suite checks many models at once, e.g. all the models of a repository in CI, and reports the results as a
+// table, as JUnit XML or as JSON.
+package suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jakub-m/formaggo/state"
+)
+
+// Suite is a named set of models.
+type Suite struct {
+	Name string
+	// Parallelism is the number of the models checked at once. Defaults to GOMAXPROCS.
+	Parallelism int
+	cases       []testCase
+}
+
+type testCase struct {
+	name  string
+	build func() state.Checker
+}
+
+// Add adds the checker to the suite.
+func (s *Suite) Add(name string, c state.Checker) {
+	s.AddFunc(name, func() state.Checker { return c })
+}
+
+// AddFunc adds a checker built only when the suite runs. A panic while building it is reported as an error of the
+// model.
+func (s *Suite) AddFunc(name string, build func() state.Checker) {
+	s.cases = append(s.cases, testCase{name: name, build: build})
+}
+
+// Result is the outcome of checking a single model.
+type Result struct {
+	Name string `json:"name"`
+	// Passed is true if there is no violation and no error.
+	Passed    bool             `json:"passed"`
+	Violation *state.Violation `json:"violation,omitempty"`
+	// Error is set if the model panicked.
+	Error   string        `json:"error,omitempty"`
+	Stats   state.Stats   `json:"stats"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Results are the results of all the models of the suite, in the order the models were added.
+type Results struct {
+	Suite   string        `json:"suite"`
+	Results []Result      `json:"results"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Run checks all the models, in parallel.
+func (s *Suite) Run() Results {
+	start := time.Now()
+	parallelism := s.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	results := make([]Result, len(s.cases))
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, tc := range s.cases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, tc testCase) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runCase(tc)
+		}(i, tc)
+	}
+	wg.Wait()
+	return Results{Suite: s.Name, Results: results, Elapsed: time.Since(start)}
+}
+
+func runCase(tc testCase) (r Result) {
+	r.Name = tc.name
+	start := time.Now()
+	defer func() {
+		r.Elapsed = time.Since(start)
+		if p := recover(); p != nil {
+			r.Passed = false
+			r.Error = fmt.Sprint(p)
+		}
+	}()
+	_, r.Stats, r.Violation = tc.build().Run()
+	r.Passed = r.Violation == nil
+	return r
+}
+
+// Failed returns the number of the models with a violation or an error.
+func (r Results) Failed() int {
+	failed := 0
+	for _, res := range r.Results {
+		if !res.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// String is a summary table with a row per model.
+func (r Results) String() string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MODEL\tRESULT\tSTATES\tELAPSED\tDETAILS\t")
+	for _, res := range r.Results {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t\n", res.Name, res.status(), res.Stats.DistinctStates, res.Elapsed.Round(time.Millisecond), res.details())
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "%d models, %d failed, %s\n", len(r.Results), r.Failed(), r.Elapsed.Round(time.Millisecond))
+	return b.String()
+}
+
+func (r Result) status() string {
+	switch {
+	case r.Error != "":
+		return "ERROR"
+	case r.Violation != nil:
+		return "VIOLATION"
+	}
+	return "PASS"
+}
+
+func (r Result) details() string {
+	switch {
+	case r.Error != "":
+		return r.Error
+	case r.Violation != nil && r.Violation.Inv != nil:
+		return "invariant " + r.Violation.Inv.Name
+	case r.Violation != nil && r.Violation.Prop != nil:
+		return "property " + r.Violation.Prop.Name
+	}
+	return ""
+}
+
+// WriteJSON writes the results as JSON. The violations are written as in Violation.MarshalJSON.
+func (r Results) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as JUnit XML, with a test case per model. A violation is a failure with the trace, a
+// panic is an error.
+func (r Results) WriteJUnit(w io.Writer) error {
+	ts := junitTestSuite{Name: r.Suite, Tests: len(r.Results), Time: seconds(r.Elapsed)}
+	for _, res := range r.Results {
+		tc := junitTestCase{Name: res.Name, Classname: r.Suite, Time: seconds(res.Elapsed)}
+		switch {
+		case res.Error != "":
+			ts.Errors++
+			tc.Error = &junitMessage{Message: res.Error, Text: res.Error}
+		case res.Violation != nil:
+			ts.Failures++
+			tc.Failure = &junitMessage{Message: res.details(), Text: res.Violation.Format(state.FormatPlain)}
+		}
+		ts.Cases = append(ts.Cases, tc)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", " ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{ts}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,73 @@
+package suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/jakub-m/formaggo/internal/testmodel"
+	"github.com/jakub-m/formaggo/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func getCountersChecker(maxA int) state.Checker {
+	c := testmodel.Checker(3)
+	c.NamedInvariants = []state.NamedInvariant{testmodel.ABelow(maxA)}
+	return c
+}
+
+func getSuite() *Suite {
+	s := &Suite{Name: "counters", Parallelism: 2}
+	s.Add("pass", getCountersChecker(3))
+	s.Add("fail", getCountersChecker(2))
+	s.AddFunc("panic", func() state.Checker { panic("boom") })
+	return s
+}
+
+func TestRun(t *testing.T) {
+	r := getSuite().Run()
+	assert.Equal(t, "counters", r.Suite)
+	if assert.Len(t, r.Results, 3) {
+		assert.Equal(t, "pass", r.Results[0].Name)
+		assert.True(t, r.Results[0].Passed)
+		assert.Equal(t, 9, r.Results[0].Stats.DistinctStates)
+		assert.False(t, r.Results[1].Passed)
+		assert.Equal(t, "ABelow2", r.Results[1].Violation.Inv.Name)
+		assert.False(t, r.Results[2].Passed)
+		assert.Equal(t, "boom", r.Results[2].Error)
+	}
+	assert.Equal(t, 2, r.Failed())
+	s := r.String()
+	assert.Regexp(t, `pass +PASS +9 `, s)
+	assert.Regexp(t, `fail +VIOLATION .*invariant ABelow2`, s)
+	assert.Regexp(t, `panic +ERROR .*boom`, s)
+	assert.Contains(t, s, "3 models, 2 failed")
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, getSuite().Run().WriteJUnit(&buf))
+	var decoded junitTestSuites
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+	if assert.Len(t, decoded.Suites, 1) {
+		ts := decoded.Suites[0]
+		assert.Equal(t, 3, ts.Tests)
+		assert.Equal(t, 1, ts.Failures)
+		assert.Equal(t, 1, ts.Errors)
+		assert.Nil(t, ts.Cases[0].Failure)
+		assert.Equal(t, "invariant ABelow2", ts.Cases[1].Failure.Message)
+		assert.Contains(t, ts.Cases[1].Failure.Text, "Violation of invariant: ABelow2")
+		assert.Equal(t, "boom", ts.Cases[2].Error.Message)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, getSuite().Run().WriteJSON(&buf))
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	results := decoded["results"].([]interface{})
+	assert.Len(t, results, 3)
+	assert.Equal(t, "ABelow2", results[1].(map[string]interface{})["violation"].(map[string]interface{})["name"])
+}
@@ -0,0 +1,153 @@
+// Package sweep checks a model for all the combinations of its constants, e.g. for all the amounts withdrawn by two
+// processes, and reports the combinations that fail.
+package sweep
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jakub-m/formaggo/state"
+	"github.com/jakub-m/formaggo/suite"
+)
+
+// Param is a named constant of the model and the values to check.
+type Param struct {
+	Name   string
+	Values []int
+}
+
+// Range is a parameter with the values from..to, inclusive.
+func Range(name string, from, to int) Param {
+	p := Param{Name: name}
+	for v := from; v <= to; v++ {
+		p.Values = append(p.Values, v)
+	}
+	return p
+}
+
+// Params are the values of the parameters of a single combination, by name.
+type Params map[string]int
+
+// Sweep builds a checker for each combination of the parameters.
+type Sweep struct {
+	Params []Param
+	Build  func(Params) state.Checker
+	// Parallelism is the number of the combinations checked at once. Defaults to GOMAXPROCS.
+	Parallelism int
+}
+
+// Result is the outcome of checking a single combination.
+type Result struct {
+	Params Params
+	suite.Result
+}
+
+// Results are the results of all the combinations, in the order of Combinations.
+type Results struct {
+	names   []string
+	Results []Result
+}
+
+// Combinations returns all the combinations of the parameters. The last parameter changes the fastest.
+func (s Sweep) Combinations() []Params {
+	combinations := []Params{{}}
+	for _, p := range s.Params {
+		next := []Params{}
+		for _, c := range combinations {
+			for _, v := range p.Values {
+				n := Params{p.Name: v}
+				for k, w := range c {
+					n[k] = w
+				}
+				next = append(next, n)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// Run checks all the combinations, in parallel.
+func (s Sweep) Run() Results {
+	ste := &suite.Suite{Parallelism: s.Parallelism}
+	combinations := s.AddTo(ste, "")
+	r := Results{names: s.names()}
+	for i, res := range ste.Run().Results {
+		r.Results = append(r.Results, Result{Params: combinations[i], Result: res})
+	}
+	return r
+}
+
+// AddTo adds all the combinations to the suite, as the models named "<name>[p1=v1,p2=v2]". Returns the combinations in
+// the order they were added.
+func (s Sweep) AddTo(ste *suite.Suite, name string) []Params {
+	combinations := s.Combinations()
+	for _, c := range combinations {
+		c := c
+		ste.AddFunc(fmt.Sprintf("%s[%s]", name, s.format(c)), func() state.Checker {
+			return s.Build(c)
+		})
+	}
+	return combinations
+}
+
+func (s Sweep) names() []string {
+	names := []string{}
+	for _, p := range s.Params {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func (s Sweep) format(c Params) string {
+	values := []string{}
+	for _, n := range s.names() {
+		values = append(values, fmt.Sprintf("%s=%d", n, c[n]))
+	}
+	return strings.Join(values, ",")
+}
+
+// Failed returns the results of the combinations with a violation or an error.
+func (r Results) Failed() []Result {
+	failed := []Result{}
+	for _, res := range r.Results {
+		if !res.Passed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// String is a table of the failed combinations, with a column per parameter.
+func (r Results) String() string {
+	var b bytes.Buffer
+	failed := r.Failed()
+	if len(failed) > 0 {
+		w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+		for _, n := range r.names {
+			fmt.Fprintf(w, "%s\t", strings.ToUpper(n))
+		}
+		fmt.Fprintln(w, "RESULT\tSTEPS\tDETAILS\t")
+		for _, res := range failed {
+			for _, n := range r.names {
+				fmt.Fprintf(w, "%d\t", res.Params[n])
+			}
+			fmt.Fprintf(w, "%s\n", describe(res))
+		}
+		w.Flush()
+	}
+	fmt.Fprintf(&b, "%d combinations, %d failed\n", len(r.Results), len(failed))
+	return b.String()
+}
+
+func describe(res Result) string {
+	switch {
+	case res.Error != "":
+		return fmt.Sprintf("ERROR\t-\t%s\t", res.Error)
+	case res.Violation.Inv != nil:
+		return fmt.Sprintf("VIOLATION\t%d\tinvariant %s\t", len(res.Violation.Trace().Transitions), res.Violation.Inv.Name)
+	}
+	return fmt.Sprintf("VIOLATION\t%d\tproperty %s\t", len(res.Violation.Trace().Transitions), res.Violation.Prop.Name)
+}
@@ -0,0 +1,61 @@
+package sweep
+
+import (
+	"testing"
+
+	"github.com/jakub-m/formaggo/internal/testmodel"
+	"github.com/jakub-m/formaggo/state"
+	"github.com/jakub-m/formaggo/suite"
+	"github.com/stretchr/testify/assert"
+)
+
+// getSweep fails when the counter A can reach maxA.
+func getSweep() Sweep {
+	return Sweep{
+		Params: []Param{Range("limit", 1, 3), {Name: "maxA", Values: []int{1, 3}}},
+		Build: func(p Params) state.Checker {
+			c := testmodel.Checker(p["limit"])
+			c.NamedInvariants = []state.NamedInvariant{testmodel.ABelow(p["maxA"])}
+			return c
+		},
+		Parallelism: 2,
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	assert.Equal(t, []Params{
+		{"limit": 1, "maxA": 1},
+		{"limit": 1, "maxA": 3},
+		{"limit": 2, "maxA": 1},
+		{"limit": 2, "maxA": 3},
+		{"limit": 3, "maxA": 1},
+		{"limit": 3, "maxA": 3},
+	}, getSweep().Combinations())
+}
+
+func TestRun(t *testing.T) {
+	r := getSweep().Run()
+	assert.Len(t, r.Results, 6)
+	failed := r.Failed()
+	if assert.Len(t, failed, 2) {
+		assert.Equal(t, Params{"limit": 2, "maxA": 1}, failed[0].Params)
+		assert.Equal(t, Params{"limit": 3, "maxA": 1}, failed[1].Params)
+		assert.Equal(t, "ABelow1", failed[0].Violation.Inv.Name)
+	}
+	assert.Equal(t, ""+
+		"LIMIT  MAXA  RESULT     STEPS  DETAILS            \n"+
+		"2      1     VIOLATION  1      invariant ABelow1  \n"+
+		"3      1     VIOLATION  1      invariant ABelow1  \n"+
+		"6 combinations, 2 failed\n", r.String())
+}
+
+func TestAddTo(t *testing.T) {
+	s := &suite.Suite{Name: "counters"}
+	getSweep().AddTo(s, "counters")
+	r := s.Run()
+	assert.Len(t, r.Results, 6)
+	assert.Equal(t, "counters[limit=1,maxA=1]", r.Results[0].Name)
+	assert.Equal(t, "counters[limit=2,maxA=1]", r.Results[2].Name)
+	assert.False(t, r.Results[2].Passed)
+	assert.Equal(t, 2, r.Failed())
+}